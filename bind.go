@@ -35,20 +35,23 @@ type Binder interface {
 	Bind(*http.Request, interface{}) error
 }
 
-type defaultBinder struct{}
+type defaultBinder struct {
+	// lenient ignores unknown fields instead of rejecting them.
+	lenient bool
+}
 
-func (defaultBinder) Bind(r *http.Request, v interface{}) error {
+func (b defaultBinder) Bind(r *http.Request, v interface{}) error {
 	if r.Body != nil {
 		defer r.Body.Close()
 	}
 
 	if r.Method == http.MethodGet {
-		return decodeValues(structTagQuery, r.URL.Query(), v)
+		return decodeValues(structTagQuery, r.URL.Query(), v, b.lenient)
 	}
 
 	switch contentType := r.Header.Get(HeaderContentType); contentType {
 	case MIMEApplicationJSON, MIMEApplicationJSONCharsetUTF8:
-		return decodeJSON(r.Body, v)
+		return decodeJSON(r.Body, v, b.lenient)
 
 	case MIMETextXML, MIMETextXMLCharsetUTF8, MIMEApplicationXML, MIMEApplicationXMLCharsetUTF8:
 		return decodeXML(r.Body, v)
@@ -58,16 +61,104 @@ func (defaultBinder) Bind(r *http.Request, v interface{}) error {
 			return err
 		}
 
-		return decodeValues(structTagForm, r.Form, v)
+		return decodeValues(structTagForm, r.Form, v, b.lenient)
 
 	default:
 		return fmt.Errorf("%w: %s", ErrBindUnsupportedContentType, contentType)
 	}
 }
 
-func decodeJSON(r io.Reader, v interface{}) error {
+// Bind unmarshals the request into v using the Binder of the Router. In contrast to the payload argument of a
+// handler, the type of v can be decided at runtime. A buffered body is rewound first, so the request can be bound more
+// than once. Errors are returned as 400 *Error.
+//
+//   if err := ctx.BufferBody(bottleneck.BufferOptions{}); err != nil {
+//     return err
+//   }
+//
+//   var event struct{ Type string }
+//   if err := ctx.Peek(&event); err != nil {
+//     return err
+//   }
+//
+//   switch event.Type {
+//   case "push":
+//     var push PushEvent
+//     return ctx.BindAndValidate(&push)
+//   }
+func (c *Context) Bind(v interface{}) error {
+	if err := c.rewindBody(); err != nil {
+		return err
+	}
+
+	binder := DefaultBinder
+	if c.router != nil && c.router.Binder != nil {
+		binder = c.router.Binder
+	}
+
+	if err := binder.Bind(c.request, v); err != nil {
+		return toBadRequestError(err)
+	}
+
+	return nil
+}
+
+// Peek unmarshals the request into v like the DefaultBinder, but ignores unknown fields, so that a part of the payload
+// can be inspected before the request is bound completely. The body must be buffered with BufferBody, otherwise
+// ErrBodyNotBuffered is returned. Other errors are returned as 400 *Error.
+func (c *Context) Peek(v interface{}) error {
+	if c.request.Method != http.MethodGet && c.request.Body != nil && c.request.Body != http.NoBody {
+		if _, ok := c.request.Body.(io.Seeker); !ok {
+			return ErrBodyNotBuffered
+		}
+	}
+
+	if err := c.rewindBody(); err != nil {
+		return err
+	}
+
+	if err := (defaultBinder{lenient: true}).Bind(c.request, v); err != nil {
+		return toBadRequestError(err)
+	}
+
+	return nil
+}
+
+// BindAndValidate unmarshals the request into v like Bind and validates it using the Validator of the Router.
+// Errors are returned as 400 *Error.
+func (c *Context) BindAndValidate(v interface{}) error {
+	if err := c.Bind(v); err != nil {
+		return err
+	}
+
+	validator := DefaultValidator
+	if c.router != nil && c.router.Validator != nil {
+		validator = c.router.Validator
+	}
+
+	if err := validator.Validate(c.request, v); err != nil {
+		return toBadRequestError(err)
+	}
+
+	return nil
+}
+
+// rewindBody seeks a buffered body back to its start. Other bodies can only be read once.
+func (c *Context) rewindBody() error {
+	if seeker, ok := c.request.Body.(io.Seeker); ok {
+		_, err := seeker.Seek(0, io.SeekStart)
+		return err
+	}
+
+	return nil
+}
+
+func decodeJSON(r io.Reader, v interface{}, lenient bool) error {
 	decoder := json.NewDecoder(r)
-	decoder.DisallowUnknownFields()
+	if !lenient {
+		decoder.DisallowUnknownFields()
+	}
+
 	return decoder.Decode(v)
 }
 
@@ -77,9 +168,9 @@ func decodeXML(r io.Reader, v interface{}) error {
 	return decoder.Decode(v)
 }
 
-func decodeValues(structTag string, values url.Values, v interface{}) error {
+func decodeValues(structTag string, values url.Values, v interface{}, lenient bool) error {
 	decoder := schema.NewDecoder()
-	decoder.IgnoreUnknownKeys(false)
+	decoder.IgnoreUnknownKeys(lenient)
 	decoder.SetAliasTag(structTag)
 	return decoder.Decode(v, values)
 }
@@ -102,3 +102,83 @@ func TestBindUnsupported(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, ErrBindUnsupportedContentType))
 }
+
+type bindTestRewindableBody struct {
+	*strings.Reader
+}
+
+func (bindTestRewindableBody) Close() error {
+	return nil
+}
+
+type bindTestValidated struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestContextBind(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Body = bindTestRewindableBody{strings.NewReader(`{"name":"Jake"}`)}
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+
+	ctx := Context{router: router, request: req}
+
+	var first, second bindTestStruct
+	assert.NoError(t, ctx.Bind(&first))
+	assert.NoError(t, ctx.Bind(&second))
+	assert.Equal(t, "Jake", first.Name)
+	assert.Equal(t, first, second)
+
+	var validated bindTestValidated
+	assert.NoError(t, ctx.BindAndValidate(&validated))
+	assert.Equal(t, "Jake", validated.Name)
+
+	var bErr *Error
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	ctx = Context{router: router, request: req}
+
+	err := ctx.BindAndValidate(&bindTestValidated{})
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set(HeaderContentType, MIMETextPlain)
+	ctx = Context{request: req}
+
+	err = ctx.Bind(&validated)
+	assert.True(t, errors.As(err, &bErr))
+	assert.True(t, errors.Is(err, ErrBindUnsupportedContentType))
+}
+
+func TestContextPeek(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		req    = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"type":"push","ref":"main"}`))
+		ctx    Context
+	)
+
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	ctx.init(httptest.NewRecorder(), req, nil)
+	ctx.router = router
+
+	var event struct {
+		Type string `json:"type"`
+	}
+
+	assert.Equal(t, ErrBodyNotBuffered, ctx.Peek(&event))
+	assert.NoError(t, ctx.BufferBody(BufferOptions{}))
+	assert.NoError(t, ctx.Peek(&event))
+	assert.Equal(t, "push", event.Type)
+
+	var push struct {
+		Type string `json:"type"`
+		Ref  string `json:"ref"`
+	}
+
+	assert.NoError(t, ctx.Bind(&push))
+	assert.Equal(t, "main", push.Ref)
+	assert.Error(t, ctx.Bind(&event))
+}
@@ -0,0 +1,151 @@
+package bottleneck
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+var (
+	// ErrBodyTooLarge indicates that a buffered request body exceeds BufferOptions.MaxSize.
+	ErrBodyTooLarge = errors.New("request body too large")
+
+	// ErrBodyNotBuffered indicates that a request body cannot be read more than once, because it is not buffered with
+	// Context.BufferBody.
+	ErrBodyNotBuffered = errors.New("request body not buffered")
+)
+
+// BufferOptions define how Context.BufferBody buffers a request body.
+type BufferOptions struct {
+	// MemoryLimit is the number of bytes kept in memory. Larger bodies are spilled to a temporary file. The default is
+	// 1 MiB.
+	MemoryLimit int64
+	// MaxSize is the maximum size of a body. Larger bodies are rejected with a 413 *Error. Zero means no limit.
+	MaxSize int64
+	// TempDir is the directory of temporary files. The default is os.TempDir.
+	TempDir string
+}
+
+// DefaultBufferOptions keep up to 1 MiB in memory and do not limit the size of a body.
+var DefaultBufferOptions = BufferOptions{MemoryLimit: 1 << 20}
+
+// A bufferedBody replaces the body of a request after buffering. It can be read many times and closing it is a noop,
+// so that a Binder cannot discard it. The temporary file is removed, when the request is done.
+type bufferedBody struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func (*bufferedBody) Close() error {
+	return nil
+}
+
+func (b *bufferedBody) release() {
+	if b.file != nil {
+		b.file.Close()
+		os.Remove(b.file.Name()) // nolint:errcheck
+	}
+}
+
+// BufferBody reads the whole request body, so that it can be read again by middleware and handlers. The body of the
+// request is replaced with a seekable reader, which Context.Bind rewinds before binding. Bodies larger than
+// opts.MemoryLimit are spilled to a temporary file. Calling BufferBody again has no effect.
+//
+// Errors while reading the body are returned unchanged, e.g. the 413 *Error of a size limiting middleware, that
+// wraps the body beforehand.
+func (c *Context) BufferBody(opts BufferOptions) error {
+	if c.body != nil || c.request.Body == nil || c.request.Body == http.NoBody {
+		return nil
+	}
+
+	if opts.MemoryLimit <= 0 {
+		opts.MemoryLimit = DefaultBufferOptions.MemoryLimit
+	}
+
+	original := c.request.Body
+	defer original.Close()
+
+	body, err := readBody(original, opts)
+	if err != nil {
+		return err
+	}
+
+	c.body = body
+	c.request.Body = body
+	return nil
+}
+
+func readBody(r io.Reader, opts BufferOptions) (*bufferedBody, error) {
+	if opts.MaxSize > 0 {
+		// Read one more byte than allowed to detect bodies, that are too large.
+		r = io.LimitReader(r, opts.MaxSize+1)
+	}
+
+	var buf bytes.Buffer
+
+	n, err := io.CopyN(&buf, r, opts.MemoryLimit+1)
+	if err == io.EOF {
+		if opts.MaxSize > 0 && n > opts.MaxSize {
+			return nil, NewError(http.StatusRequestEntityTooLarge).WithCause(ErrBodyTooLarge)
+		}
+
+		return &bufferedBody{SectionReader: io.NewSectionReader(bytes.NewReader(buf.Bytes()), 0, n)}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ioutil.TempFile(opts.TempDir, "bottleneck-body-")
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bufferedBody{file: file}
+
+	size, err := io.Copy(file, io.MultiReader(&buf, r))
+	if err != nil {
+		body.release()
+		return nil, err
+	}
+
+	if opts.MaxSize > 0 && size > opts.MaxSize {
+		body.release()
+		return nil, NewError(http.StatusRequestEntityTooLarge).WithCause(ErrBodyTooLarge)
+	}
+
+	body.SectionReader = io.NewSectionReader(file, 0, size)
+	return body, nil
+}
+
+// RawBody returns a reader of the whole request body, that is independent of the request body and other calls to
+// RawBody. The body is buffered with DefaultBufferOptions, unless BufferBody was called before.
+//
+//   body, err := ctx.RawBody()
+//   if err != nil {
+//     return err
+//   }
+//
+//   mac := hmac.New(sha256.New, secret)
+//   io.Copy(mac, body)
+func (c *Context) RawBody() (io.ReadSeeker, error) {
+	if err := c.BufferBody(DefaultBufferOptions); err != nil {
+		return nil, err
+	}
+
+	if c.body == nil {
+		return bytes.NewReader(nil), nil
+	}
+
+	return io.NewSectionReader(c.body, 0, c.body.Size()), nil
+}
+
+// release frees the resources of a request after it is handled.
+func (c *Context) release() {
+	if c.body != nil {
+		c.body.release()
+	}
+}
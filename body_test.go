@@ -0,0 +1,111 @@
+package bottleneck
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bodyTestContext(body string) *Context {
+	var ctx Context
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+
+	ctx.init(httptest.NewRecorder(), req, nil)
+	return &ctx
+}
+
+func TestContextBufferBody(t *testing.T) {
+	for _, memoryLimit := range []int64{0, 4} {
+		ctx := bodyTestContext(`{"name":"Jake"}`)
+		assert.NoError(t, ctx.BufferBody(BufferOptions{MemoryLimit: memoryLimit, MaxSize: 64}))
+
+		raw, err := ctx.RawBody()
+		assert.NoError(t, err)
+
+		b, err := ioutil.ReadAll(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"Jake"}`, string(b))
+
+		var first, second bindTestStruct
+		assert.NoError(t, ctx.Bind(&first))
+		assert.NoError(t, ctx.Bind(&second))
+		assert.Equal(t, "Jake", first.Name)
+		assert.Equal(t, first, second)
+
+		raw, err = ctx.RawBody()
+		assert.NoError(t, err)
+
+		b, err = ioutil.ReadAll(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"Jake"}`, string(b))
+
+		ctx.release()
+	}
+}
+
+func TestContextBufferBodySpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bottleneck-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := bodyTestContext(strings.Repeat("x", 32))
+	assert.NoError(t, ctx.BufferBody(BufferOptions{MemoryLimit: 8, TempDir: dir}))
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1)
+
+	raw, err := ctx.RawBody()
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(raw)
+	assert.NoError(t, err)
+	assert.Len(t, b, 32)
+
+	ctx.release()
+
+	files, _ = ioutil.ReadDir(dir)
+	assert.Len(t, files, 0)
+}
+
+func TestContextBufferBodyTooLarge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bottleneck-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, memoryLimit := range []int64{8, 64} {
+		var bErr *Error
+
+		ctx := bodyTestContext(strings.Repeat("x", 32))
+		err := ctx.BufferBody(BufferOptions{MemoryLimit: memoryLimit, MaxSize: 16, TempDir: dir})
+
+		assert.True(t, errors.As(err, &bErr))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, bErr.Status)
+		assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 0)
+
+	ctx := bodyTestContext(strings.Repeat("x", 16))
+	assert.NoError(t, ctx.BufferBody(BufferOptions{MaxSize: 16}))
+}
+
+func TestContextRawBodyEmpty(t *testing.T) {
+	var ctx Context
+	ctx.init(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	raw, err := ctx.RawBody()
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(raw)
+	assert.NoError(t, err)
+	assert.Empty(t, b)
+}
@@ -0,0 +1,192 @@
+// Package client is the runtime used by generated bottleneck clients.
+//
+// It encodes payloads the same way the bottleneck.DefaultBinder decodes them and turns error responses back into
+// *bottleneck.Error values.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/schema"
+	"github.com/lukasdietrich/bottleneck"
+)
+
+const structTagQuery = "query"
+
+// ErrNoVersionSource indicates that a Version does not specify how it is sent.
+var ErrNoVersionSource = errors.New("version without header, query or vendor")
+
+// A Client performs requests against a bottleneck Router.
+type Client struct {
+	// BaseURL is prepended to the path of every request. It must not end with a slash.
+	BaseURL string
+	// HTTPClient is used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// New creates a new Client for the given base url.
+//
+//   c := client.New("https://api.example.com")
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Version is the api version of a request. It is sent the way the bottleneck.VersionSource of the Router expects it,
+// so only one of Header, Query or Vendor should be set.
+type Version struct {
+	// Value is the version itself, e.g. "2".
+	Value string
+	// Header sends the version in a custom header. See bottleneck.HeaderVersion.
+	Header string
+	// Query sends the version as query parameter. See bottleneck.QueryVersion.
+	Query string
+	// Vendor sends the version in the Accept header as vendor media type "application/vnd.<vendor>.v<value>+json".
+	// See bottleneck.MediaTypeVersion.
+	Vendor string
+}
+
+// Do sends a request to path. The payload is encoded as query for GET requests and as JSON otherwise. If result is not
+// nil, a successful response is decoded as JSON into it. Responses with a status >= 400 are returned as
+// *bottleneck.Error.
+func (c *Client) Do(ctx context.Context, method, path string, payload, result interface{}) error {
+	return c.DoVersion(ctx, method, path, Version{}, payload, result)
+}
+
+// DoVersion sends a request to path like Do, but requests a specific api version.
+//
+//   err := c.DoVersion(ctx, "GET", "/users", client.Version{Value: "2", Vendor: "acme"}, nil, &users)
+func (c *Client) DoVersion(
+	ctx context.Context, method, path string, version Version, payload, result interface{},
+) error {
+	req, err := c.newRequest(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	if err := version.apply(req); err != nil {
+		return err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return decodeError(res)
+	}
+
+	if result == nil || res.StatusCode == http.StatusNoContent {
+		_, err = io.Copy(ioutil.Discard, res.Body)
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, payload interface{}) (*http.Request, error) {
+	var (
+		target = c.BaseURL + path
+		body   io.Reader
+	)
+
+	if payload != nil {
+		if method == http.MethodGet {
+			query, err := encodeQuery(payload)
+			if err != nil {
+				return nil, err
+			}
+
+			target += "?" + query
+		} else {
+			b, err := json.Marshal(payload)
+			if err != nil {
+				return nil, err
+			}
+
+			body = bytes.NewReader(b)
+		}
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set(bottleneck.HeaderContentType, bottleneck.MIMEApplicationJSONCharsetUTF8)
+	}
+
+	return req.WithContext(ctx), nil
+}
+
+func (v Version) apply(req *http.Request) error {
+	if v.Value == "" {
+		return nil
+	}
+
+	switch {
+	case v.Header != "":
+		req.Header.Set(v.Header, v.Value)
+
+	case v.Query != "":
+		query := req.URL.Query()
+		query.Set(v.Query, v.Value)
+		req.URL.RawQuery = query.Encode()
+
+	case v.Vendor != "":
+		req.Header.Set(bottleneck.HeaderAccept, "application/vnd."+strings.ToLower(v.Vendor)+".v"+v.Value+"+json")
+
+	default:
+		return fmt.Errorf("%w: version %s", ErrNoVersionSource, v.Value)
+	}
+
+	return nil
+}
+
+func encodeQuery(payload interface{}) (string, error) {
+	values := make(url.Values)
+
+	encoder := schema.NewEncoder()
+	encoder.SetAliasTag(structTagQuery)
+
+	if err := encoder.Encode(payload, values); err != nil {
+		return "", err
+	}
+
+	return values.Encode(), nil
+}
+
+func decodeError(res *http.Response) error {
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	e := bottleneck.NewError(res.StatusCode)
+	if len(b) > 0 {
+		// The body is not necessarily a bottleneck.Error (e.g. a proxy error page), so the status text is kept then.
+		// nolint:errcheck
+		json.Unmarshal(b, e)
+	}
+
+	return e
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+)
+
+type clientTestContext struct {
+	bottleneck.Context
+}
+
+type clientTestRequest struct {
+	Name string `json:"name" query:"name"`
+}
+
+type clientTestResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func newClientTestServer() *httptest.Server {
+	var (
+		router = bottleneck.NewRouter(clientTestContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	greet := func(ctx *clientTestContext, req *clientTestRequest) error {
+		if req.Name == "" {
+			return bottleneck.NewError(http.StatusUnprocessableEntity).WithMessage("name missing")
+		}
+
+		return ctx.JSON(http.StatusOK, clientTestResponse{Greeting: "Hello " + req.Name})
+	}
+
+	group.GET("/greet", greet)
+	group.POST("/greet", greet)
+	router.Mount(group)
+
+	return httptest.NewServer(router)
+}
+
+func TestClientDo(t *testing.T) {
+	server := newClientTestServer()
+	defer server.Close()
+
+	c := New(server.URL + "/")
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		var res clientTestResponse
+
+		assert.NoError(t, c.Do(context.Background(), method, "/greet", &clientTestRequest{Name: "Jake"}, &res))
+		assert.Equal(t, "Hello Jake", res.Greeting)
+	}
+}
+
+func TestClientDoError(t *testing.T) {
+	server := newClientTestServer()
+	defer server.Close()
+
+	var (
+		c    = New(server.URL)
+		err  = c.Do(context.Background(), http.MethodPost, "/greet", &clientTestRequest{}, nil)
+		bErr *bottleneck.Error
+	)
+
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, bErr.Status)
+	assert.Equal(t, "name missing", bErr.Message)
+}
+
+func TestClientDoVersion(t *testing.T) {
+	for source, version := range map[string]Version{
+		"header": {Value: "2", Header: "X-API-Version"},
+		"query":  {Value: "2", Query: "version"},
+		"vendor": {Value: "2", Vendor: "acme"},
+	} {
+		var (
+			router = bottleneck.NewRouter(clientTestContext{})
+			v2     = bottleneck.NewGroup().WithVersion("2")
+		)
+
+		switch source {
+		case "header":
+			router.Versioning.Source = bottleneck.HeaderVersion("X-API-Version")
+		case "query":
+			router.Versioning.Source = bottleneck.QueryVersion("version")
+		case "vendor":
+			router.Versioning.Source = bottleneck.MediaTypeVersion("acme")
+		}
+
+		v2.GET("/greet", func(ctx *clientTestContext, req *clientTestRequest) error {
+			return ctx.JSON(http.StatusOK, clientTestResponse{Greeting: "Hi " + req.Name})
+		})
+		router.Mount(v2)
+
+		server := httptest.NewServer(router)
+
+		var (
+			c   = New(server.URL)
+			res clientTestResponse
+			err = c.DoVersion(context.Background(), http.MethodGet, "/greet", version, &clientTestRequest{Name: "Jake"}, &res)
+		)
+
+		assert.NoError(t, err, source)
+		assert.Equal(t, "Hi Jake", res.Greeting, source)
+
+		err = c.DoVersion(context.Background(), http.MethodGet, "/greet", Version{Value: "2"}, nil, nil)
+		assert.True(t, errors.Is(err, ErrNoVersionSource))
+
+		server.Close()
+	}
+}
@@ -0,0 +1,207 @@
+// Package codegen generates Go and TypeScript clients for the routes of a bottleneck.Router.
+//
+// The generators are meant to be called from a small program inside the service repository, which builds the
+// Router and is run by go generate:
+//
+//   //go:generate go run ./cmd/genclient
+//
+//   func main() {
+//     router := api.NewRouter()
+//
+//     if err := codegen.WriteGoClientFile("client/client.go", router.Routes(), codegen.GoClientOptions{
+//       Package: "client",
+//     }); err != nil {
+//       log.Fatal(err)
+//     }
+//
+//     if err := codegen.WriteTypeScriptFile("web/src/api.ts", router.Routes(), codegen.TypeScriptOptions{
+//       BaseURL: "/api",
+//     }); err != nil {
+//       log.Fatal(err)
+//     }
+//   }
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lukasdietrich/bottleneck"
+)
+
+var (
+	// ErrUnsupportedType indicates that a payload type cannot be referenced in generated code.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrNoVersionOptions indicates that routes with a version are generated without VersionOptions.
+	ErrNoVersionOptions = errors.New("versioned routes require version options")
+	// ErrDuplicateOperation indicates that two routes map to the same operation name, e.g. the same method and path
+	// mounted with Router.Mount and Router.MountHost. Such routes must be filtered before generating a client.
+	ErrDuplicateOperation = errors.New("duplicate operation name")
+)
+
+// VersionOptions define how generated clients send the version of routes added with Group.WithVersion. They must
+// match the bottleneck.VersionSource of the Router, so exactly one field should be set.
+type VersionOptions struct {
+	// Header is the custom header read by bottleneck.HeaderVersion.
+	Header string
+	// Query is the query parameter read by bottleneck.QueryVersion.
+	Query string
+	// Vendor is the vendor of the media type read by bottleneck.MediaTypeVersion.
+	Vendor string
+}
+
+func (o VersionOptions) check(route bottleneck.RouteInfo) error {
+	if route.Version != "" && o.Header == "" && o.Query == "" && o.Vendor == "" {
+		return fmt.Errorf("%s %s: %w", route.Method, route.Path, ErrNoVersionOptions)
+	}
+
+	return nil
+}
+
+// accept returns the vendor media type of a version.
+func (o VersionOptions) accept(version string) string {
+	return "application/vnd." + strings.ToLower(o.Vendor) + ".v" + version + "+json"
+}
+
+// checkOperationNames returns ErrDuplicateOperation, if two routes, that are not skipped by the generators, share an
+// operation name.
+func checkOperationNames(routes []bottleneck.RouteInfo) error {
+	names := make(map[string]bottleneck.RouteInfo)
+
+	for _, route := range routes {
+		if route.WebSocket {
+			continue
+		}
+
+		name := operationName(route)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%w: %s for %s %s (host %q) and %s %s (host %q)", ErrDuplicateOperation, name,
+				other.Method, other.Path, other.Host, route.Method, route.Path, route.Host)
+		}
+
+		names[name] = route
+	}
+
+	return nil
+}
+
+// pathSegment is a single part of a route path. It is either a literal string or a named parameter.
+type pathSegment struct {
+	literal string
+	param   string
+}
+
+// parsePath splits a route path into literals and parameters.
+//
+//   "/users/:id/posts" => ["/users/", :id, "/posts"]
+func parsePath(path string) []pathSegment {
+	var (
+		segments []pathSegment
+		literal  strings.Builder
+	)
+
+	for _, part := range strings.SplitAfter(path, "/") {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			if literal.Len() > 0 {
+				segments = append(segments, pathSegment{literal: literal.String()})
+				literal.Reset()
+			}
+
+			// Constraints like ":id<int>" are not part of the parameter name.
+			name := strings.TrimSuffix(part[1:], "/")
+			if i := strings.IndexByte(name, '<'); i >= 0 {
+				name = name[:i]
+			}
+
+			segments = append(segments, pathSegment{param: name})
+
+			if strings.HasSuffix(part, "/") {
+				literal.WriteString("/")
+			}
+		} else {
+			literal.WriteString(part)
+		}
+	}
+
+	if literal.Len() > 0 {
+		segments = append(segments, pathSegment{literal: literal.String()})
+	}
+
+	return segments
+}
+
+// pathParams returns the names of all parameters of a route path.
+func pathParams(path string) []string {
+	var params []string
+
+	for _, segment := range parsePath(path) {
+		if segment.param != "" {
+			params = append(params, segment.param)
+		}
+	}
+
+	return params
+}
+
+// operationName derives a name in PascalCase from the http method, path and version of a route.
+//
+//   GET /api/users/:id => GetApiUsersById
+//   GET /api/users (version 2) => GetApiUsersV2
+func operationName(route bottleneck.RouteInfo) string {
+	var name strings.Builder
+	name.WriteString(pascalCase(strings.ToLower(route.Method)))
+
+	for _, segment := range parsePath(route.Path) {
+		if segment.param != "" {
+			name.WriteString("By")
+			name.WriteString(pascalCase(segment.param))
+		} else {
+			name.WriteString(pascalCase(segment.literal))
+		}
+	}
+
+	if route.Version != "" {
+		name.WriteString("V")
+		name.WriteString(pascalCase(route.Version))
+	}
+
+	return name.String()
+}
+
+// pascalCase converts arbitrary text into an identifier by capitalizing the first letter of every word and dropping
+// everything, that is neither a letter nor a digit.
+func pascalCase(s string) string {
+	var (
+		name  strings.Builder
+		upper = true
+	)
+
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+
+			name.WriteRune(r)
+			upper = false
+
+		default:
+			upper = true
+		}
+	}
+
+	return name.String()
+}
+
+// camelCase converts arbitrary text into an identifier starting with a lower case letter.
+func camelCase(s string) string {
+	name := []rune(pascalCase(s))
+	if len(name) > 0 {
+		name[0] = unicode.ToLower(name[0])
+	}
+
+	return string(name)
+}
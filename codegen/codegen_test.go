@@ -0,0 +1,36 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	assert.Equal(t, []pathSegment{
+		{literal: "/users/"},
+		{param: "id"},
+		{literal: "/posts/"},
+		{param: "filepath"},
+	}, parsePath("/users/:id/posts/*filepath"))
+
+	assert.Equal(t, []pathSegment{
+		{literal: "/users/"},
+		{param: "id"},
+	}, parsePath("/users/:id<int>"))
+
+	assert.Equal(t, []pathSegment{{literal: "/"}}, parsePath("/"))
+}
+
+func TestOperationName(t *testing.T) {
+	for expected, route := range map[string]bottleneck.RouteInfo{
+		"Get":               {Method: "GET", Path: "/"},
+		"GetApiUsersById":   {Method: "GET", Path: "/api/users/:id"},
+		"PostUserSettings":  {Method: "POST", Path: "/user-settings"},
+		"DeleteFilesByPath": {Method: "DELETE", Path: "/files/*path"},
+		"GetUsersV2":        {Method: "GET", Path: "/users", Version: "2"},
+	} {
+		assert.Equal(t, expected, operationName(route))
+	}
+}
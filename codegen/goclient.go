@@ -0,0 +1,266 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukasdietrich/bottleneck"
+)
+
+const importClient = "github.com/lukasdietrich/bottleneck/client"
+
+// GoClientOptions define how a Go client is generated.
+type GoClientOptions struct {
+	// Package is the name of the generated package. If empty "client" is used.
+	Package string
+	// TypeName is the name of the generated client type. If empty "Client" is used.
+	TypeName string
+	// Version defines how the methods of versioned routes send their version.
+	Version VersionOptions
+}
+
+func (o *GoClientOptions) defaults() {
+	if o.Package == "" {
+		o.Package = "client"
+	}
+
+	if o.TypeName == "" {
+		o.TypeName = "Client"
+	}
+}
+
+// GenerateGoClient writes the source of a Go package to w, that contains a typed method for each route.
+//
+// Path parameters become string arguments and the payload of a handler becomes a typed argument. Payloads are sent
+// the way the bottleneck.DefaultBinder expects them: as query for GET requests and as JSON otherwise. A successful
+// response is decoded as JSON into the result argument, if it is not nil. Error responses are returned as
+// *bottleneck.Error.
+//
+// Payload types must be named types of an importable package, because the generated code references them. WebSocket
+// routes are skipped. Methods of versioned routes send their version as configured by GoClientOptions.Version. Routes,
+// that share a method and path across hosts, result in ErrDuplicateOperation.
+func GenerateGoClient(w io.Writer, routes []bottleneck.RouteInfo, opts GoClientOptions) error {
+	opts.defaults()
+
+	if err := checkOperationNames(routes); err != nil {
+		return err
+	}
+
+	var (
+		imports = newImportSet()
+		body    bytes.Buffer
+	)
+
+	imports.add("context")
+	imports.add(importClient)
+
+	fmt.Fprintf(&body, "// %s is a typed client for the api.\n", opts.TypeName)
+	fmt.Fprintf(&body, "type %s struct {\n*client.Client\n}\n\n", opts.TypeName)
+	fmt.Fprintf(&body, "// New%s creates a new %s for the given base url.\n", opts.TypeName, opts.TypeName)
+	fmt.Fprintf(&body, "func New%s(baseURL string) *%s {\n", opts.TypeName, opts.TypeName)
+	fmt.Fprintf(&body, "return &%s{Client: client.New(baseURL)}\n}\n", opts.TypeName)
+
+	for _, route := range routes {
+		if route.WebSocket {
+			continue
+		}
+
+		if err := writeGoMethod(&body, imports, opts, route); err != nil {
+			return err
+		}
+	}
+
+	var src bytes.Buffer
+
+	fmt.Fprintf(&src, "// Code generated by bottleneck/codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", opts.Package)
+	imports.write(&src)
+	body.WriteTo(&src) // nolint:errcheck
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+// WriteGoClientFile generates a Go client using GenerateGoClient and writes it to filename.
+func WriteGoClientFile(filename string, routes []bottleneck.RouteInfo, opts GoClientOptions) error {
+	var buf bytes.Buffer
+
+	if err := GenerateGoClient(&buf, routes, opts); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func writeGoMethod(w io.Writer, imports *importSet, opts GoClientOptions, route bottleneck.RouteInfo) error {
+	if err := opts.Version.check(route); err != nil {
+		return err
+	}
+
+	var (
+		name   = operationName(route)
+		params = pathParams(route.Path)
+		args   = []string{"ctx context.Context"}
+	)
+
+	for _, param := range params {
+		args = append(args, goParamName(param)+" string")
+	}
+
+	payload := "nil"
+	if route.Payload != nil {
+		payloadName, err := imports.typeName(route.Payload)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+
+		args = append(args, "payload *"+payloadName)
+		payload = "payload"
+	}
+
+	args = append(args, "result interface{}")
+
+	var (
+		method = route.Method
+		path   = goPathExpr(imports, route.Path)
+	)
+
+	fmt.Fprintf(w, "\n// %s sends a %s request to %s.\n", name, route.Method, route.Path)
+	fmt.Fprintf(w, "func (c *%s) %s(%s) error {\n", opts.TypeName, name, strings.Join(args, ", "))
+
+	if route.Version == "" {
+		fmt.Fprintf(w, "return c.Do(ctx, %q, %s, %s, result)\n}\n", method, path, payload)
+	} else {
+		fields := []string{fmt.Sprintf("Value: %q", route.Version)}
+
+		for _, field := range [][2]string{
+			{"Header", opts.Version.Header},
+			{"Query", opts.Version.Query},
+			{"Vendor", opts.Version.Vendor},
+		} {
+			if field[1] != "" {
+				fields = append(fields, fmt.Sprintf("%s: %q", field[0], field[1]))
+			}
+		}
+
+		version := "client.Version{" + strings.Join(fields, ", ") + "}"
+
+		fmt.Fprintf(w, "return c.DoVersion(ctx, %q, %s, %s, %s, result)\n}\n", method, path, version, payload)
+	}
+
+	return nil
+}
+
+// goPathExpr creates a Go expression, that builds the path of a route from its escaped parameters.
+func goPathExpr(imports *importSet, path string) string {
+	var parts []string
+
+	for _, segment := range parsePath(path) {
+		if segment.param != "" {
+			imports.add("net/url")
+			parts = append(parts, "url.PathEscape("+goParamName(segment.param)+")")
+		} else {
+			parts = append(parts, strconv.Quote(segment.literal))
+		}
+	}
+
+	if len(parts) == 0 {
+		return `"/"`
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+func goParamName(param string) string {
+	name := camelCase(param)
+	if name == "" || name == "ctx" || name == "payload" || name == "result" || name == "c" || name == "url" {
+		name = "p" + pascalCase(param)
+	}
+
+	return name
+}
+
+// importSet collects the packages used by generated code and assigns unique names to them.
+type importSet struct {
+	names map[string]string // path => name
+	taken map[string]bool   // name => used
+}
+
+func newImportSet() *importSet {
+	return &importSet{
+		names: make(map[string]string),
+		taken: make(map[string]bool),
+	}
+}
+
+func (s *importSet) add(pkgPath string) string {
+	if name, ok := s.names[pkgPath]; ok {
+		return name
+	}
+
+	var (
+		base = strings.Map(func(r rune) rune {
+			if r == '-' || r == '.' {
+				return -1
+			}
+
+			return r
+		}, path.Base(pkgPath))
+		name = base
+	)
+
+	for i := 2; s.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	s.names[pkgPath] = name
+	s.taken[name] = true
+
+	return name
+}
+
+// typeName returns a qualified reference to the named type t and imports its package.
+func (s *importSet) typeName(t reflect.Type) (string, error) {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return "", fmt.Errorf("%w: %v is not a named type", ErrUnsupportedType, t)
+	}
+
+	if t.PkgPath() == "main" || !ast.IsExported(t.Name()) {
+		return "", fmt.Errorf("%w: %v cannot be imported", ErrUnsupportedType, t)
+	}
+
+	return s.add(t.PkgPath()) + "." + t.Name(), nil
+}
+
+func (s *importSet) write(w io.Writer) {
+	paths := make([]string, 0, len(s.names))
+	for pkgPath := range s.names {
+		paths = append(paths, pkgPath)
+	}
+
+	sort.Strings(paths)
+
+	fmt.Fprintln(w, "import (")
+	for _, pkgPath := range paths {
+		if name := s.names[pkgPath]; name != path.Base(pkgPath) {
+			fmt.Fprintf(w, "%s %q\n", name, pkgPath)
+		} else {
+			fmt.Fprintf(w, "%q\n", pkgPath)
+		}
+	}
+	fmt.Fprintln(w, ")")
+}
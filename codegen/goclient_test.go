@@ -0,0 +1,154 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+)
+
+type goClientTestContext struct {
+	bottleneck.Context
+}
+
+// GoClientTestRequest must be exported to be referenced by the generated client.
+type GoClientTestRequest struct {
+	Name string `json:"name"`
+}
+
+// goClientTestFileSet is shared by all type-checks, so that imported packages are only loaded once.
+var goClientTestFileSet = token.NewFileSet()
+
+var goClientTestTypes = goClientTestImporter{importer.ForCompiler(goClientTestFileSet, "source", nil)}
+
+// goClientTestImporter resolves the codegen package to a stub, because the payload types of the tests are not part
+// of the package outside of tests.
+type goClientTestImporter struct {
+	types.Importer
+}
+
+func (i goClientTestImporter) Import(path string) (*types.Package, error) {
+	if path != "github.com/lukasdietrich/bottleneck/codegen" {
+		return i.Importer.Import(path)
+	}
+
+	var (
+		pkg     = types.NewPackage(path, "codegen")
+		name    = types.NewTypeName(token.NoPos, pkg, "GoClientTestRequest", nil)
+		request = types.NewStruct([]*types.Var{types.NewField(token.NoPos, pkg, "Name", types.Typ[types.String], false)}, nil)
+	)
+
+	types.NewNamed(name, request, nil)
+	pkg.Scope().Insert(name)
+	pkg.MarkComplete()
+
+	return pkg, nil
+}
+
+// checkGoClient parses and type-checks the generated source, so that code, which is well-formed but does not
+// compile, fails the test.
+func checkGoClient(t *testing.T, src string) {
+	fset := goClientTestFileSet
+
+	file, err := parser.ParseFile(fset, "client.go", src, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	config := types.Config{Importer: goClientTestTypes}
+	_, err = config.Check("api", fset, []*ast.File{file}, nil)
+	assert.NoError(t, err)
+}
+
+func TestGenerateGoClient(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(goClientTestContext{})
+		group  = bottleneck.NewGroup().WithPrefix("/api")
+		buf    bytes.Buffer
+	)
+
+	group.GET("/users", func(*goClientTestContext, *GoClientTestRequest) error { return nil })
+	group.PUT("/users/:id", func(*goClientTestContext, *GoClientTestRequest) error { return nil })
+	group.DELETE("/users/:id", func(*goClientTestContext) error { return nil })
+	router.Mount(group)
+
+	assert.NoError(t, GenerateGoClient(&buf, router.Routes(), GoClientOptions{Package: "api"}))
+
+	src := buf.String()
+	checkGoClient(t, src)
+
+	assert.Contains(t, src, "package api")
+	assert.Contains(t, src, `"github.com/lukasdietrich/bottleneck/codegen"`)
+	assert.Contains(t, src, "func (c *Client) GetApiUsers(ctx context.Context, payload *codegen.GoClientTestRequest, result interface{}) error")
+	assert.Contains(t, src, `return c.Do(ctx, "PUT", "/api/users/"+url.PathEscape(id), payload, result)`)
+	assert.Contains(t, src, "func (c *Client) DeleteApiUsersById(ctx context.Context, id string, result interface{}) error")
+}
+
+func TestGenerateGoClientUnnamedPayload(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(goClientTestContext{})
+		group  = bottleneck.NewGroup()
+		buf    bytes.Buffer
+	)
+
+	group.POST("/", func(*goClientTestContext, *struct{ Name string }) error { return nil })
+	router.Mount(group)
+
+	err := GenerateGoClient(&buf, router.Routes(), GoClientOptions{})
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestGenerateGoClientVersion(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(goClientTestContext{})
+		group  = bottleneck.NewGroup().WithVersion("2")
+		buf    bytes.Buffer
+	)
+
+	group.GET("/users", func(*goClientTestContext) error { return nil })
+	router.Mount(group)
+
+	err := GenerateGoClient(&buf, router.Routes(), GoClientOptions{Package: "api"})
+	assert.True(t, errors.Is(err, ErrNoVersionOptions))
+
+	buf.Reset()
+	assert.NoError(t, GenerateGoClient(&buf, router.Routes(), GoClientOptions{
+		Package: "api",
+		Version: VersionOptions{Header: "X-API-Version"},
+	}))
+
+	src := buf.String()
+	checkGoClient(t, src)
+
+	assert.Contains(t, src, "func (c *Client) GetUsersV2(ctx context.Context, result interface{}) error")
+	assert.Contains(t, src,
+		`return c.DoVersion(ctx, "GET", "/users", client.Version{Value: "2", Header: "X-API-Version"}, nil, result)`)
+}
+
+func TestGenerateGoClientDuplicateOperation(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(goClientTestContext{})
+		group  = bottleneck.NewGroup()
+		admin  = bottleneck.NewGroup()
+		buf    bytes.Buffer
+	)
+
+	group.GET("/users", func(*goClientTestContext) error { return nil })
+	admin.GET("/users", func(*goClientTestContext) error { return nil })
+	router.Mount(group)
+	router.MountHost("admin.example.com", admin)
+
+	err := GenerateGoClient(&buf, router.Routes(), GoClientOptions{Package: "api"})
+	assert.True(t, errors.Is(err, ErrDuplicateOperation))
+
+	err = GenerateTypeScript(&buf, router.Routes(), TypeScriptOptions{})
+	assert.True(t, errors.Is(err, ErrDuplicateOperation))
+}
@@ -0,0 +1,540 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukasdietrich/bottleneck"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errorType         = reflect.TypeOf(bottleneck.Error{})
+)
+
+const errorInterfaceName = "BottleneckError"
+
+// TypeScriptOptions define how TypeScript types and functions are generated.
+type TypeScriptOptions struct {
+	// BaseURL is the initial value of the exported config.baseURL, which is prepended to every path.
+	BaseURL string
+	// Version defines how the functions of versioned routes send their version.
+	Version VersionOptions
+}
+
+// GenerateTypeScript writes TypeScript source to w, that contains an interface for every payload struct and a fetch
+// based function for every route.
+//
+// Field names follow the json struct tags of the payloads and fields tagged with omitempty are optional. Payloads of
+// GET requests are sent as query using the query struct tags, all other payloads are sent as JSON. Error responses
+// are thrown as ApiError, which wraps the BottleneckError interface derived from bottleneck.Error. WebSocket routes are
+// skipped. Functions of versioned routes send their version as configured by TypeScriptOptions.Version. Routes, that
+// share a method and path across hosts, result in ErrDuplicateOperation.
+func GenerateTypeScript(w io.Writer, routes []bottleneck.RouteInfo, opts TypeScriptOptions) error {
+	if err := checkOperationNames(routes); err != nil {
+		return err
+	}
+
+	var (
+		types = newTSTypeSet()
+		funcs bytes.Buffer
+	)
+
+	if _, err := types.interfaceName(errorType); err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		if route.WebSocket {
+			continue
+		}
+
+		if err := writeTSFunction(&funcs, types, opts.Version, route); err != nil {
+			return err
+		}
+	}
+
+	var src bytes.Buffer
+
+	fmt.Fprintf(&src, "// Code generated by bottleneck/codegen. DO NOT EDIT.\n\n")
+	types.write(&src)
+	fmt.Fprintf(&src, tsRuntime, strconv.Quote(opts.BaseURL), errorInterfaceName, errorInterfaceName, errorInterfaceName)
+	funcs.WriteTo(&src) // nolint:errcheck
+
+	_, err := src.WriteTo(w)
+	return err
+}
+
+// WriteTypeScriptFile generates TypeScript using GenerateTypeScript and writes it to filename.
+func WriteTypeScriptFile(filename string, routes []bottleneck.RouteInfo, opts TypeScriptOptions) error {
+	var buf bytes.Buffer
+
+	if err := GenerateTypeScript(&buf, routes, opts); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+const tsRuntime = `export const config: { baseURL: string; init: RequestInit } = {
+  baseURL: %s,
+  init: {},
+};
+
+export class ApiError extends Error {
+  constructor(public readonly error: %s) {
+    super(error.message);
+  }
+}
+
+interface Version {
+  value: string;
+  header?: string;
+  query?: string;
+  accept?: string;
+}
+
+async function request<T>(
+  method: string,
+  path: string,
+  body: unknown,
+  query: Record<string, unknown> | undefined,
+  init: RequestInit | undefined,
+  version?: Version,
+): Promise<T> {
+  const options: RequestInit = { ...config.init, ...init, method };
+  const headers = new Headers(options.headers);
+  let url = config.baseURL + path;
+
+  if (version?.header !== undefined) {
+    headers.set(version.header, version.value);
+  }
+  if (version?.accept !== undefined) {
+    headers.set("Accept", version.accept);
+  }
+  if (version?.query !== undefined) {
+    query = { ...query, [version.query]: version.value };
+  }
+
+  if (query !== undefined) {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      for (const item of Array.isArray(value) ? value : [value]) {
+        if (item !== undefined && item !== null) {
+          params.append(key, String(item));
+        }
+      }
+    }
+    url += "?" + params.toString();
+  }
+
+  if (body !== undefined) {
+    headers.set("Content-Type", "application/json");
+    options.body = JSON.stringify(body);
+  }
+
+  options.headers = headers;
+
+  const res = await fetch(url, options);
+  if (!res.ok) {
+    let error: %s = { status: res.status, message: res.statusText };
+    try {
+      error = (await res.json()) as %s;
+    } catch {
+      // The body is not a bottleneck error, so the status text is used instead.
+    }
+    throw new ApiError(error);
+  }
+
+  if (res.status === 204) {
+    return undefined as unknown as T;
+  }
+
+  return (await res.json()) as T;
+}
+`
+
+func writeTSFunction(w io.Writer, types *tsTypeSet, versionOpts VersionOptions, route bottleneck.RouteInfo) error {
+	if err := versionOpts.check(route); err != nil {
+		return err
+	}
+
+	var (
+		name   = camelCase(operationName(route))
+		args   []string
+		body   = "undefined"
+		query  = "undefined"
+		params = make(map[string]string)
+	)
+
+	for _, param := range pathParams(route.Path) {
+		params[param] = tsParamName(param)
+		args = append(args, params[param]+": string")
+	}
+
+	if route.Payload != nil {
+		payloadName, err := types.interfaceName(route.Payload)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+
+		args = append(args, "payload: "+payloadName)
+
+		if route.Method == "GET" {
+			query = tsQueryExpr(route.Payload)
+		} else {
+			body = "payload"
+		}
+	}
+
+	args = append(args, "init?: RequestInit")
+
+	fmt.Fprintf(w, "\n/** %s sends a %s request to %s. */\n", name, route.Method, route.Path)
+	fmt.Fprintf(w, "export function %s<T = unknown>(%s): Promise<T> {\n", name, strings.Join(args, ", "))
+	version := ""
+	if route.Version != "" {
+		version = ", " + tsVersionExpr(versionOpts, route.Version)
+	}
+
+	fmt.Fprintf(w, "  return request<T>(%q, %s, %s, %s, init%s);\n}\n",
+		route.Method, tsPathExpr(route.Path, params), body, query, version)
+
+	return nil
+}
+
+// tsVersionExpr creates a Version object literal, that sends the version of a route.
+func tsVersionExpr(opts VersionOptions, version string) string {
+	entries := []string{fmt.Sprintf("value: %q", version)}
+
+	if opts.Header != "" {
+		entries = append(entries, fmt.Sprintf("header: %q", opts.Header))
+	}
+
+	if opts.Query != "" {
+		entries = append(entries, fmt.Sprintf("query: %q", opts.Query))
+	}
+
+	if opts.Vendor != "" {
+		entries = append(entries, fmt.Sprintf("accept: %q", opts.accept(version)))
+	}
+
+	return "{ " + strings.Join(entries, ", ") + " }"
+}
+
+// tsQueryExpr creates an object literal, that maps the json names of the payload fields to their query names.
+func tsQueryExpr(t reflect.Type) string {
+	var entries []string
+
+	for _, field := range tsFields(t) {
+		queryName := field.goName
+		if tag, ok := field.tag.Lookup("query"); ok {
+			queryName = strings.Split(tag, ",")[0]
+		}
+
+		if queryName == "-" {
+			continue
+		}
+
+		entries = append(entries, fmt.Sprintf("%q: payload[%q]", queryName, field.name))
+	}
+
+	return "{ " + strings.Join(entries, ", ") + " }"
+}
+
+func tsPathExpr(path string, params map[string]string) string {
+	var expr strings.Builder
+	expr.WriteString("`")
+
+	for _, segment := range parsePath(path) {
+		if segment.param != "" {
+			fmt.Fprintf(&expr, "${encodeURIComponent(%s)}", params[segment.param])
+		} else {
+			expr.WriteString(strings.NewReplacer("`", "\\`", "$", "\\$").Replace(segment.literal))
+		}
+	}
+
+	if expr.Len() == 1 {
+		expr.WriteString("/")
+	}
+
+	expr.WriteString("`")
+	return expr.String()
+}
+
+func tsParamName(param string) string {
+	name := camelCase(param)
+	if name == "" || name == "payload" || name == "init" {
+		name = "p" + pascalCase(param)
+	}
+
+	return name
+}
+
+// tsField is an exported struct field as it appears in JSON.
+type tsField struct {
+	name     string
+	goName   string
+	tag      reflect.StructTag
+	optional bool
+	typ      reflect.Type
+}
+
+// tsFields lists the fields of a struct the way encoding/json would encode them. Embedded structs without a name are
+// flattened into the parent.
+func tsFields(t reflect.Type) []tsField {
+	var fields []tsField
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			field     = t.Field(i)
+			tag, ok   = field.Tag.Lookup("json")
+			tagParts  = strings.Split(tag, ",")
+			name      = tagParts[0]
+			fieldType = field.Type
+		)
+
+		if name == "-" && len(tagParts) == 1 {
+			continue
+		}
+
+		if field.Anonymous && (!ok || name == "") {
+			embedded := fieldType
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, tsFields(embedded)...)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		f := tsField{
+			name:   name,
+			goName: field.Name,
+			tag:    field.Tag,
+			typ:    fieldType,
+		}
+
+		for _, option := range tagParts[1:] {
+			switch option {
+			case "omitempty":
+				f.optional = true
+			case "string":
+				f.typ = reflect.TypeOf("")
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// tsTypeSet collects the interfaces, that are referenced by generated code.
+type tsTypeSet struct {
+	names map[reflect.Type]string
+	taken map[string]bool
+	decls map[string]string
+}
+
+func newTSTypeSet() *tsTypeSet {
+	return &tsTypeSet{
+		names: make(map[reflect.Type]string),
+		taken: make(map[string]bool),
+		decls: make(map[string]string),
+	}
+}
+
+// interfaceName returns the name of the interface for a struct type and declares it, if it is new.
+func (s *tsTypeSet) interfaceName(t reflect.Type) (string, error) {
+	if name, ok := s.names[t]; ok {
+		return name, nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("%w: %v is not a struct", ErrUnsupportedType, t)
+	}
+
+	base := pascalCase(t.Name())
+	if t == errorType {
+		base = errorInterfaceName
+	} else if base == "" {
+		return "", fmt.Errorf("%w: %v is not a named type", ErrUnsupportedType, t)
+	}
+
+	name := base
+	for i := 2; s.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	// Register the name before declaring the fields to allow recursive types.
+	s.names[t] = name
+	s.taken[name] = true
+
+	var decl strings.Builder
+
+	fmt.Fprintf(&decl, "export interface %s {\n", name)
+	for _, field := range tsFields(t) {
+		fieldType, err := s.typeExpr(field.typ)
+		if err != nil {
+			return "", fmt.Errorf("%v.%s: %w", t, field.goName, err)
+		}
+
+		optional := ""
+		if field.optional {
+			optional = "?"
+		}
+
+		fmt.Fprintf(&decl, "  %s%s: %s;\n", tsPropertyName(field.name), optional, fieldType)
+	}
+	fmt.Fprintf(&decl, "}\n")
+
+	s.decls[name] = decl.String()
+	return name, nil
+}
+
+// typeExpr converts a Go type into the TypeScript type of its JSON representation.
+func (s *tsTypeSet) typeExpr(t reflect.Type) (string, error) {
+	switch {
+	case t == timeType:
+		return "string", nil
+
+	case t == rawMessageType:
+		return "unknown", nil
+
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return "unknown", nil
+
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return "string", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean", nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number", nil
+
+	case reflect.String:
+		return "string", nil
+
+	case reflect.Interface:
+		return "unknown", nil
+
+	case reflect.Ptr:
+		elem, err := s.typeExpr(t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		return elem + " | null", nil
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings.
+			return "string", nil
+		}
+
+		elem, err := s.typeExpr(t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+
+		if t.Kind() == reflect.Slice {
+			return elem + "[] | null", nil
+		}
+
+		return elem + "[]", nil
+
+	case reflect.Map:
+		elem, err := s.typeExpr(t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		return "Record<string, " + elem + "> | null", nil
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.inlineStruct(t)
+		}
+
+		return s.interfaceName(t)
+
+	default:
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedType, t)
+	}
+}
+
+func (s *tsTypeSet) inlineStruct(t reflect.Type) (string, error) {
+	var props []string
+
+	for _, field := range tsFields(t) {
+		fieldType, err := s.typeExpr(field.typ)
+		if err != nil {
+			return "", err
+		}
+
+		optional := ""
+		if field.optional {
+			optional = "?"
+		}
+
+		props = append(props, fmt.Sprintf("%s%s: %s", tsPropertyName(field.name), optional, fieldType))
+	}
+
+	return "{ " + strings.Join(props, "; ") + " }", nil
+}
+
+func (s *tsTypeSet) write(w io.Writer) {
+	names := make([]string, 0, len(s.decls))
+	for name := range s.decls {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\n", s.decls[name])
+	}
+}
+
+// tsPropertyName quotes a property name, if it is not a valid identifier.
+func tsPropertyName(name string) string {
+	for i, r := range name {
+		isLetter := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return strconv.Quote(name)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+)
+
+type tsTestContext struct {
+	bottleneck.Context
+}
+
+type tsTestAddress struct {
+	Street string `json:"street"`
+}
+
+type tsTestUser struct {
+	ID        int               `json:"id" query:"user_id"`
+	Name      string            `json:"name,omitempty"`
+	Tags      []string          `json:"tags"`
+	Address   *tsTestAddress    `json:"address"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Secret    string            `json:"-"`
+	internal  string
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(tsTestContext{})
+		group  = bottleneck.NewGroup()
+		buf    bytes.Buffer
+	)
+
+	group.GET("/users", func(*tsTestContext, *tsTestUser) error { return nil })
+	group.PUT("/users/:id", func(*tsTestContext, *tsTestUser) error { return nil })
+	group.DELETE("/users/:id", func(ctx *tsTestContext) error { return ctx.String(http.StatusOK, "") })
+	router.Mount(group)
+
+	assert.NoError(t, GenerateTypeScript(&buf, router.Routes(), TypeScriptOptions{BaseURL: "/api"}))
+
+	src := buf.String()
+
+	assert.Contains(t, src, "export interface BottleneckError {\n  status: number;\n  message: string;\n  requestId?: string;\n}")
+	assert.Contains(t, src, "export interface TsTestAddress {\n  street: string;\n}")
+	assert.Contains(t, src, "export interface TsTestUser {\n"+
+		"  id: number;\n"+
+		"  name?: string;\n"+
+		"  tags: string[] | null;\n"+
+		"  address: TsTestAddress | null;\n"+
+		"  labels?: Record<string, string> | null;\n"+
+		"  createdAt: string;\n"+
+		"}")
+	assert.Contains(t, src, `baseURL: "/api",`)
+	assert.Contains(t, src, "export function getUsers<T = unknown>(payload: TsTestUser, init?: RequestInit): Promise<T> {\n"+
+		`  return request<T>("GET", `+"`/users`"+`, undefined, { "user_id": payload["id"], "Name": payload["name"], `)
+	assert.Contains(t, src, "export function putUsersById<T = unknown>(id: string, payload: TsTestUser, init?: RequestInit): Promise<T> {\n"+
+		`  return request<T>("PUT", `+"`/users/${encodeURIComponent(id)}`"+`, payload, undefined, init);`)
+	assert.Contains(t, src, "export function deleteUsersById<T = unknown>(id: string, init?: RequestInit): Promise<T> {")
+}
+
+func TestGenerateTypeScriptVersion(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(tsTestContext{})
+		group  = bottleneck.NewGroup().WithVersion("2")
+		buf    bytes.Buffer
+	)
+
+	group.GET("/users", func(*tsTestContext) error { return nil })
+	router.Mount(group)
+
+	err := GenerateTypeScript(&buf, router.Routes(), TypeScriptOptions{})
+	assert.True(t, errors.Is(err, ErrNoVersionOptions))
+
+	buf.Reset()
+	assert.NoError(t, GenerateTypeScript(&buf, router.Routes(), TypeScriptOptions{
+		Version: VersionOptions{Vendor: "acme"},
+	}))
+
+	assert.Contains(t, buf.String(), "export function getUsersV2<T = unknown>(init?: RequestInit): Promise<T> {\n"+
+		`  return request<T>("GET", `+"`/users`"+`, undefined, undefined, init, `+
+		`{ value: "2", accept: "application/vnd.acme.v2+json" });`)
+}
@@ -1,17 +1,37 @@
 package bottleneck
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+)
+
+var (
+	// ErrHijackNotSupported indicates that the underlying http.ResponseWriter does not implement http.Hijacker.
+	ErrHijackNotSupported = errors.New("response writer does not support hijacking")
+	// ErrInvalidRedirect indicates that Context.Redirect is called with a status, that is not a redirect.
+	ErrInvalidRedirect = errors.New("invalid redirect status")
 )
 
 // Response wraps a raw http.ResponseWriter and stores additional information, which is not tracked
 // by the standard library.
+//
+// Response implements http.Flusher, http.Hijacker and http.Pusher, if the underlying Writer does.
 type Response struct {
 	// Status is the http status code that is set during WriteHeader.
 	Status int
 	// Writer is the raw http.ResponseWriter. It can be set in a middleware to change the way data is written.
 	Writer http.ResponseWriter
+
+	size      int64
+	committed bool
+	before    []func()
 }
 
 // Header returns the header map that will be sent by WriteHeader.
@@ -21,27 +41,118 @@ func (r *Response) Header() http.Header {
 	return r.Writer.Header()
 }
 
-// Write writes the data to the connection as part of an HTTP reply.
+// Write writes the data to the connection as part of an HTTP reply. If WriteHeader has not yet been called, Write
+// calls WriteHeader with the current Status before writing the data.
 //
 // See https://golang.org/pkg/net/http/#ResponseWriter
 func (r *Response) Write(b []byte) (int, error) {
-	return r.Writer.Write(b)
+	if !r.committed {
+		r.WriteHeader(r.Status)
+	}
+
+	n, err := r.Writer.Write(b)
+	r.size += int64(n)
+	return n, err
 }
 
-// WriteHeader sends an HTTP response header with the provided status code.
+// WriteHeader sends an HTTP response header with the provided status code. All functions registered with Before are
+// called right before the header is sent. Only the first call has an effect, subsequent calls are ignored.
 //
 // See https://golang.org/pkg/net/http/#ResponseWriter
 func (r *Response) WriteHeader(status int) {
+	if r.committed {
+		return
+	}
+
 	r.Status = status
-	r.Writer.WriteHeader(status)
+	r.runBefore()
+
+	r.committed = true
+	r.Writer.WriteHeader(r.Status)
+}
+
+func (r *Response) runBefore() {
+	for _, fn := range r.before {
+		fn()
+	}
+}
+
+// Before registers a function, that is called right before the header is sent. It may still modify the header and
+// the Status.
+//
+//   ctx.Response().Before(func() {
+//     ctx.Response().Header().Set("X-Response-Time", time.Since(t0).String())
+//   })
+func (r *Response) Before(fn func()) {
+	r.before = append(r.before, fn)
+}
+
+// Committed reports whether the header has already been sent.
+func (r *Response) Committed() bool {
+	return r.committed
+}
+
+// Size returns the number of body bytes written so far.
+func (r *Response) Size() int64 {
+	return r.size
+}
+
+// Flush sends any buffered data to the client. The header is sent first, if it has not been sent yet.
+//
+// See https://golang.org/pkg/net/http/#Flusher
+func (r *Response) Flush() {
+	if !r.committed {
+		r.WriteHeader(r.Status)
+	}
+
+	if flusher, ok := r.Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection. ErrHijackNotSupported is returned, if the underlying Writer does
+// not support it.
+//
+// See https://golang.org/pkg/net/http/#Hijacker
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.Writer.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrHijackNotSupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.committed = true
+	}
+
+	return conn, rw, err
+}
+
+// Push initiates an HTTP/2 server push. http.ErrNotSupported is returned, if the underlying Writer does not support
+// it.
+//
+// See https://golang.org/pkg/net/http/#Pusher
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := r.Writer.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return pusher.Push(target, opts)
 }
 
 // Context is the base for custom contexts. It is a container for the raw http request and response and provides
 // convenience methods to access request-data and to write responses.
 type Context struct {
+	router   *Router
 	request  *http.Request
 	response *Response
 	params   map[string]string
+	query    url.Values
+	body     *bufferedBody
+	id       string
+	values   map[interface{}]interface{}
+	json     *JSONOptions
 }
 
 func (c *Context) init(res http.ResponseWriter, req *http.Request, params map[string]string) {
@@ -51,6 +162,9 @@ func (c *Context) init(res http.ResponseWriter, req *http.Request, params map[st
 	}
 	c.request = req
 	c.params = params
+	c.query = nil
+	c.body = nil
+	c.id = ""
 }
 
 // Request returns the raw http request.
@@ -58,38 +172,145 @@ func (c *Context) Request() *http.Request {
 	return c.request
 }
 
+// SetRequest replaces the raw http request for the rest of the request handling. Middleware can use it to attach a
+// context.Context with deadlines or values.
+//
+//   ctx.SetRequest(ctx.Request().WithContext(traceCtx))
+func (c *Context) SetRequest(req *http.Request) {
+	c.request = req
+	c.query = nil
+}
+
+// RequestContext returns the context.Context of the raw http request. It is cancelled, when the client disconnects.
+//
+//   func handler(ctx *CustomContext) error {
+//     return db.QueryContext(ctx.RequestContext(), ...)
+//   }
+func (c *Context) RequestContext() context.Context {
+	return c.request.Context()
+}
+
 // Response returns the raw http response.
 func (c *Context) Response() *Response {
 	return c.response
 }
 
+// Set stores a value for the duration of the request. Keys should be of an unexported type to avoid collisions,
+// just like keys of context.Context. The store is shared between all middleware and handlers of a request,
+// regardless of whether they use a custom context or the base Context.
+//
+//   type userKey struct{}
+//
+//   ctx.Set(userKey{}, user)
+func (c *Context) Set(key, value interface{}) {
+	if c.values == nil {
+		c.values = make(map[interface{}]interface{})
+	}
+
+	c.values[key] = value
+}
+
+// Get returns a value stored with Set. The second return value reports whether the key exists.
+//
+//   user, ok := ctx.Get(userKey{})
+func (c *Context) Get(key interface{}) (interface{}, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// RequestID returns the id of the request set with SetRequestID or an empty string. It is included in error
+// responses, so that clients can refer to a specific request.
+func (c *Context) RequestID() string {
+	return c.id
+}
+
+// SetRequestID sets the id of the request. It is usually called by a middleware like middleware.RequestID.
+func (c *Context) SetRequestID(id string) {
+	c.id = id
+}
+
 // Param returns the path parameter of the current matched route. If the parameter does not exist, an empty string is
 // returned instead.
 //
 //   router.GET("/users/:name", func(ctx *Context) error {
 //     return ctx.String(http.StatusOK, ctx.Param("name"))
 //   })
+//
+// Parameters may be constrained with "int", "uuid" or a regular expression in angle brackets. Requests with segments,
+// that do not match the constraint, are answered as if the route did not exist.
+//
+//   router.GET("/users/:id<int>", ...)
+//   router.GET("/posts/:slug<[a-z-]+>", ...)
+//   router.GET("/files/:uuid<uuid>", ...)
 func (c *Context) Param(key string) string {
 	return c.params[key]
 }
 
-// Query returns the query value for a given key. If the key does not exist, an empty string is returned instead.
+// JSONOptions returns the options used to render JSON. These are the JSONOptions of the Router, unless they are
+// replaced with SetJSONOptions.
+func (c *Context) JSONOptions() JSONOptions {
+	switch {
+	case c.json != nil:
+		return *c.json
+	case c.router != nil:
+		return c.router.JSON
+	default:
+		return DefaultJSONOptions
+	}
+}
+
+// SetJSONOptions replaces the options used to render JSON for the rest of the request.
 //
-//   // curl "localhost:8080/search?input=Does router performance matter in Go?"
-//   router.GET("/search", func(ctx *Context) error {
-//     return ctx.String(http.StatusOK, ctx.Query("input"))
-//   })
-func (c *Context) Query(key string) string {
-	return c.request.URL.Query().Get(key)
+//   opts := ctx.JSONOptions()
+//   opts.Stream = true
+//   ctx.SetJSONOptions(opts)
+func (c *Context) SetJSONOptions(opts JSONOptions) {
+	c.json = &opts
 }
 
-// Render writes a generic response using the provided Renderer after the status-code is set.
+// Render writes a generic response using the provided Renderer after the status-code is set. A JSONRenderer without
+// Options uses the JSONOptions of the Context.
 func (c *Context) Render(status int, r Renderer) error {
+	r = c.withJSONOptions(r)
 	r.Header(c.response.Header())
 	c.response.WriteHeader(status)
 	return r.Render(c.response)
 }
 
+// renderBuffered writes a response like Render, but renders the body into a buffer first. An error of the Renderer
+// is returned before anything is written, so that it can still be displayed to the client.
+func (c *Context) renderBuffered(status int, r Renderer) error {
+	r = c.withJSONOptions(r)
+
+	header := make(http.Header)
+	r.Header(header)
+
+	var body bytes.Buffer
+	if err := r.Render(&body); err != nil {
+		return err
+	}
+
+	for key, values := range header {
+		for _, value := range values {
+			c.response.Header().Add(key, value)
+		}
+	}
+
+	c.response.WriteHeader(status)
+	_, err := body.WriteTo(c.response)
+	return err
+}
+
+func (c *Context) withJSONOptions(r Renderer) Renderer {
+	if jr, ok := r.(JSONRenderer); ok && jr.Options == nil {
+		opts := c.JSONOptions()
+		jr.Options = &opts
+		return jr
+	}
+
+	return r
+}
+
 // String writes a response using the StringRenderer.
 func (c *Context) String(status int, value string) error {
 	return c.Render(status, StringRenderer{String: value})
@@ -105,6 +326,111 @@ func (c *Context) XML(status int, value interface{}) error {
 	return c.Render(status, XMLRenderer{Value: value})
 }
 
+// YAML writes a response using the YAMLRenderer.
+func (c *Context) YAML(status int, value interface{}) error {
+	return c.Render(status, YAMLRenderer{Value: value})
+}
+
+// CSV writes a response using the CSVRenderer.
+//
+//   type Row struct {
+//     Name  string `csv:"name"`
+//     Email string `csv:"email"`
+//   }
+//
+//   return ctx.CSV(http.StatusOK, []Row{...})
+func (c *Context) CSV(status int, value interface{}) error {
+	return c.Render(status, CSVRenderer{Value: value})
+}
+
+// NDJSON writes a response using the NDJSONRenderer. Values from a channel or func are streamed to the client as they
+// are produced.
+//
+//   return ctx.NDJSON(http.StatusOK, func(yield func(interface{}) error) error {
+//     for rows.Next() {
+//       ...
+//       if err := yield(row); err != nil {
+//         return err
+//       }
+//     }
+//
+//     return rows.Err()
+//   })
+func (c *Context) NDJSON(status int, values interface{}) error {
+	return c.Render(status, NDJSONRenderer{Values: values})
+}
+
+// MessagePack writes a response using the MessagePackRenderer.
+func (c *Context) MessagePack(status int, value interface{}) error {
+	return c.Render(status, MessagePackRenderer{Value: value})
+}
+
+// JSONP writes a response using the JSONPRenderer. A 400 *Error is returned, if the callback is not a valid
+// javascript identifier.
+//
+//   return ctx.JSONP(http.StatusOK, ctx.Query("callback"), value)
+func (c *Context) JSONP(status int, callback string, value interface{}) error {
+	if !jsonpCallbackPattern.MatchString(callback) {
+		return NewError(http.StatusBadRequest).WithCause(ErrInvalidCallback)
+	}
+
+	return c.Render(status, JSONPRenderer{Callback: callback, Value: value})
+}
+
+// Redirect redirects the request to the url, which may be relative to the path of the request. The status must be
+// one of 300, 301, 302, 303, 307 or 308, otherwise ErrInvalidRedirect is returned.
+//
+//   return ctx.Redirect(http.StatusSeeOther, "/login")
+func (c *Context) Redirect(status int, url string) error {
+	switch status {
+	case http.StatusMultipleChoices,
+		http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("%w: status %d", ErrInvalidRedirect, status)
+	}
+
+	http.Redirect(c.response, c.request, url, status)
+	return nil
+}
+
+// NoContent writes a response without body.
+//
+//   return ctx.NoContent(http.StatusNoContent)
+func (c *Context) NoContent(status int) error {
+	c.response.WriteHeader(status)
+	return nil
+}
+
+// Negotiate writes a response using the Renderer, that is chosen by the Negotiation of the Router based on the Accept
+// header of the request. A 406 *Error is returned, if no Renderer is acceptable. The body is rendered before the
+// status-code is written, so that an error of the Renderer is returned instead of an incomplete response.
+//
+//   router.GET("/users/:id", func(ctx *Context) error {
+//     return ctx.Negotiate(http.StatusOK, user)
+//   })
+func (c *Context) Negotiate(status int, value interface{}) error {
+	c.response.Header().Add(HeaderVary, HeaderAccept)
+
+	r, err := c.negotiation().Renderer(c.request, value)
+	if err != nil {
+		return err
+	}
+
+	return c.renderBuffered(status, r)
+}
+
+func (c *Context) negotiation() *Negotiation {
+	if c.router != nil && c.router.Negotiation != nil {
+		return c.router.Negotiation
+	}
+
+	return defaultNegotiation
+}
+
 // Stream writes a response using the StreamRenderer.
 func (c *Context) Stream(status int, contentType string, reader io.Reader) error {
 	return c.Render(status, StreamRenderer{
@@ -2,10 +2,13 @@ package bottleneck
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -111,3 +114,228 @@ func TestContextRenderStream(t *testing.T) {
 	assert.Equal(t, MIMEOctetStream, recorder.Header().Get(HeaderContentType))
 	assert.Equal(t, "Hello World", buf.String())
 }
+
+type contextTestRow struct {
+	Name    string    `csv:"name" yaml:"name"`
+	Age     int       `csv:"age" yaml:"age"`
+	Joined  time.Time `csv:"joined" yaml:"-"`
+	Ignored string    `csv:"-" yaml:"-"`
+}
+
+func TestContextRenderYAML(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+	)
+
+	ctx.init(recorder, nil, nil)
+
+	assert.NoError(t, ctx.YAML(http.StatusOK, contextTestRow{Name: "Jake", Age: 28}))
+	assert.Equal(t, MIMEApplicationYAMLCharsetUTF8, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, "name: Jake\nage: 28\n", recorder.Body.String())
+}
+
+func TestContextRenderCSV(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+		joined   = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	ctx.init(recorder, nil, nil)
+
+	assert.NoError(t, ctx.CSV(http.StatusOK, []*contextTestRow{
+		{Name: "Jake", Age: 28, Joined: joined, Ignored: "x"},
+		{Name: "Doe, John", Age: 42, Joined: joined},
+	}))
+	assert.Equal(t, MIMETextCSVCharsetUTF8, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, "name,age,joined\n"+
+		"Jake,28,2020-01-02T03:04:05Z\n"+
+		"\"Doe, John\",42,2020-01-02T03:04:05Z\n", recorder.Body.String())
+
+	assert.True(t, errors.Is(CSVRenderer{Value: 42}.Render(recorder), ErrUnsupportedCSV))
+}
+
+func TestContextRenderNDJSON(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+		values   = make(chan bindTestStruct, 2)
+	)
+
+	ctx.init(recorder, nil, nil)
+
+	values <- bindTestStruct{Name: "Jake"}
+	values <- bindTestStruct{Name: "Joe"}
+	close(values)
+
+	assert.NoError(t, ctx.NDJSON(http.StatusOK, values))
+	assert.Equal(t, MIMEApplicationNDJSON, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, "{\"name\":\"Jake\"}\n{\"name\":\"Joe\"}\n", recorder.Body.String())
+	assert.True(t, recorder.Flushed)
+
+	recorder = httptest.NewRecorder()
+	ctx.init(recorder, nil, nil)
+
+	assert.NoError(t, ctx.NDJSON(http.StatusOK, func(yield func(interface{}) error) error {
+		for i := 1; i <= 3; i++ {
+			if err := yield(i); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+	assert.Equal(t, "1\n2\n3\n", recorder.Body.String())
+}
+
+func TestContextRenderMessagePack(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+	)
+
+	ctx.init(recorder, nil, nil)
+
+	assert.NoError(t, ctx.MessagePack(http.StatusOK, bindTestStruct{Name: "Jake"}))
+	assert.Equal(t, MIMEApplicationMessagePack, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa4, 'J', 'a', 'k', 'e'}, recorder.Body.Bytes())
+}
+
+func TestContextRenderJSONP(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+	)
+
+	ctx.init(recorder, nil, nil)
+
+	assert.NoError(t, ctx.JSONP(http.StatusOK, "app.receive", bindTestStruct{Name: "Jake"}))
+	assert.Equal(t, MIMEApplicationJavaScriptCharsetUTF8, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, `/**/ typeof app.receive === 'function' && app.receive({"name":"Jake"});`, recorder.Body.String())
+
+	err := ctx.JSONP(http.StatusOK, "alert(1);x", nil)
+	assert.True(t, errors.Is(err, ErrInvalidCallback))
+	assert.Equal(t, http.StatusBadRequest, err.(*Error).Status)
+}
+
+func TestContextRedirect(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+	)
+
+	ctx.init(recorder, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	assert.True(t, errors.Is(ctx.Redirect(http.StatusOK, "/login"), ErrInvalidRedirect))
+	assert.True(t, errors.Is(ctx.Redirect(http.StatusNotModified, "/login"), ErrInvalidRedirect))
+	assert.True(t, errors.Is(ctx.Redirect(http.StatusUseProxy, "/login"), ErrInvalidRedirect))
+	assert.True(t, errors.Is(ctx.Redirect(306, "/login"), ErrInvalidRedirect))
+	assert.NoError(t, ctx.Redirect(http.StatusSeeOther, "/login"))
+	assert.Equal(t, http.StatusSeeOther, recorder.Code)
+	assert.Equal(t, "/login", recorder.Header().Get("Location"))
+}
+
+func TestContextNoContent(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+	)
+
+	ctx.init(recorder, nil, nil)
+
+	assert.NoError(t, ctx.NoContent(http.StatusNoContent))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, 0, recorder.Body.Len())
+}
+
+type contextTestKey struct{}
+
+func TestContextRequestContext(t *testing.T) {
+	var (
+		ctx    Context
+		parent = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+
+	ctx.init(httptest.NewRecorder(), parent, nil)
+	assert.Equal(t, parent.Context(), ctx.RequestContext())
+
+	child := parent.WithContext(context.WithValue(parent.Context(), contextTestKey{}, "value"))
+	ctx.SetRequest(child)
+
+	assert.Equal(t, child, ctx.Request())
+	assert.Equal(t, "value", ctx.RequestContext().Value(contextTestKey{}))
+
+	custom := routerTestContext{Context: ctx}
+	assert.Equal(t, "value", custom.RequestContext().Value(contextTestKey{}))
+}
+
+func TestContextValues(t *testing.T) {
+	var ctx Context
+
+	value, ok := ctx.Get(contextTestKey{})
+	assert.False(t, ok)
+	assert.Nil(t, value)
+
+	ctx.Set(contextTestKey{}, 42)
+
+	value, ok = ctx.Get(contextTestKey{})
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+}
+
+func TestResponseWriteHeaderOnce(t *testing.T) {
+	var (
+		recorder = httptest.NewRecorder()
+		res      = Response{Writer: recorder, Status: http.StatusOK}
+		calls    int
+	)
+
+	res.Before(func() {
+		calls++
+		res.Header().Set("X-Before", "called")
+	})
+
+	assert.False(t, res.Committed())
+
+	res.WriteHeader(http.StatusCreated)
+	res.WriteHeader(http.StatusInternalServerError)
+
+	n, err := res.Write([]byte("Hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	assert.True(t, res.Committed())
+	assert.Equal(t, int64(5), res.Size())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, http.StatusCreated, res.Status)
+	assert.Equal(t, "called", recorder.Header().Get("X-Before"))
+}
+
+func TestResponseImplicitWriteHeader(t *testing.T) {
+	var (
+		recorder = httptest.NewRecorder()
+		res      = Response{Writer: recorder, Status: http.StatusAccepted}
+	)
+
+	res.Write([]byte("Hello"))
+	res.Flush()
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.True(t, recorder.Flushed)
+}
+
+func TestResponseOptionalInterfaces(t *testing.T) {
+	res := Response{Writer: httptest.NewRecorder()}
+
+	_, _, err := res.Hijack()
+	assert.True(t, errors.Is(err, ErrHijackNotSupported))
+	assert.True(t, errors.Is(res.Push("/style.css", nil), http.ErrNotSupported))
+
+	var (
+		_ http.Flusher  = &res
+		_ http.Hijacker = &res
+		_ http.Pusher   = &res
+	)
+}
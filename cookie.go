@@ -0,0 +1,237 @@
+package bottleneck
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInvalidCookie indicates that a signed or encrypted cookie was tampered with or was written with an unknown
+	// key.
+	ErrInvalidCookie = errors.New("invalid cookie")
+	// ErrNoCookieKeys indicates that signed or encrypted cookies are used without CookieOptions.Keys.
+	ErrNoCookieKeys = errors.New("no cookie keys configured")
+)
+
+// CookieOptions define the defaults of cookies written with Context.SetCookie and the keys of signed and encrypted
+// cookies.
+type CookieOptions struct {
+	// Keys are used to sign and encrypt cookies. New cookies are written with the first key, but cookies written with
+	// any of the keys are accepted. Keys can be rotated by prepending a new key and removing old keys later. Keys
+	// should be at least 32 random bytes.
+	Keys [][]byte
+	// Insecure stops Context.SetCookie from setting the Secure attribute, e.g. for local development over http.
+	Insecure bool
+	// SameSite is set on cookies without a SameSite attribute. The default is http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+// Cookie returns the named cookie of the request or http.ErrNoCookie.
+func (c *Context) Cookie(name string) (*http.Cookie, error) {
+	return c.request.Cookie(name)
+}
+
+// SetCookie adds a Set-Cookie header to the response. Unless configured otherwise with Router.Cookies, the Secure
+// attribute is set, the Path defaults to "/" and SameSite defaults to Lax. Cookies, that are not needed by scripts,
+// should also be HttpOnly.
+//
+//   ctx.SetCookie(&http.Cookie{Name: "theme", Value: "dark", MaxAge: 86400})
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	opts := c.cookieOptions()
+	cookie = copyCookie(cookie)
+
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+
+	if cookie.SameSite == 0 {
+		cookie.SameSite = opts.SameSite
+		if cookie.SameSite == 0 {
+			cookie.SameSite = http.SameSiteLaxMode
+		}
+	}
+
+	if !opts.Insecure {
+		cookie.Secure = true
+	}
+
+	http.SetCookie(c.response, cookie)
+}
+
+// SignedCookie returns the named cookie of the request after verifying its signature. A 400 *Error wrapping
+// ErrInvalidCookie is returned, if the signature does not match any of the keys.
+func (c *Context) SignedCookie(name string) (*http.Cookie, error) {
+	return c.decodeCookie(name, verifyCookieValue)
+}
+
+// SetSignedCookie adds a Set-Cookie header with a signed value. The value is readable by the client, but cannot be
+// changed without the keys of Router.Cookies.
+func (c *Context) SetSignedCookie(cookie *http.Cookie) error {
+	return c.encodeCookie(cookie, signCookieValue)
+}
+
+// EncryptedCookie returns the named cookie of the request after decrypting and authenticating its value. A 400
+// *Error wrapping ErrInvalidCookie is returned, if the value cannot be decrypted with any of the keys.
+func (c *Context) EncryptedCookie(name string) (*http.Cookie, error) {
+	return c.decodeCookie(name, decryptCookieValue)
+}
+
+// SetEncryptedCookie adds a Set-Cookie header with a value encrypted using AES-GCM. The value can neither be read nor
+// changed without the keys of Router.Cookies.
+//
+//   err := ctx.SetEncryptedCookie(&http.Cookie{Name: "session", Value: sessionID, HttpOnly: true})
+func (c *Context) SetEncryptedCookie(cookie *http.Cookie) error {
+	return c.encodeCookie(cookie, encryptCookieValue)
+}
+
+func (c *Context) cookieOptions() CookieOptions {
+	if c.router != nil {
+		return c.router.Cookies
+	}
+
+	return CookieOptions{}
+}
+
+type cookieEncoder func(key []byte, name, value string) (string, error)
+
+type cookieDecoder func(key []byte, name, value string) (string, bool)
+
+func (c *Context) encodeCookie(cookie *http.Cookie, encode cookieEncoder) error {
+	keys := c.cookieOptions().Keys
+	if len(keys) == 0 {
+		return ErrNoCookieKeys
+	}
+
+	value, err := encode(keys[0], cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+
+	cookie = copyCookie(cookie)
+	cookie.Value = value
+
+	c.SetCookie(cookie)
+	return nil
+}
+
+func (c *Context) decodeCookie(name string, decode cookieDecoder) (*http.Cookie, error) {
+	keys := c.cookieOptions().Keys
+	if len(keys) == 0 {
+		return nil, ErrNoCookieKeys
+	}
+
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if value, ok := decode(key, name, cookie.Value); ok {
+			cookie.Value = value
+			return cookie, nil
+		}
+	}
+
+	return nil, NewError(http.StatusBadRequest).WithCause(fmt.Errorf("%w: %s", ErrInvalidCookie, name))
+}
+
+func copyCookie(cookie *http.Cookie) *http.Cookie {
+	copied := *cookie
+	return &copied
+}
+
+// deriveCookieKey derives independent keys for signing and encryption from a configured key.
+func deriveCookieKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	io.WriteString(mac, purpose) // nolint:errcheck
+	return mac.Sum(nil)
+}
+
+// cookieMAC authenticates the name together with the value, so that values cannot be moved between cookies.
+func cookieMAC(key []byte, name, value string) []byte {
+	mac := hmac.New(sha256.New, deriveCookieKey(key, "bottleneck signed cookie"))
+	io.WriteString(mac, name+"|"+value) // nolint:errcheck
+	return mac.Sum(nil)
+}
+
+func signCookieValue(key []byte, name, value string) (string, error) {
+	var (
+		encodedValue = base64.RawURLEncoding.EncodeToString([]byte(value))
+		signature    = base64.RawURLEncoding.EncodeToString(cookieMAC(key, name, value))
+	)
+
+	return encodedValue + "." + signature, nil
+}
+
+func verifyCookieValue(key []byte, name, value string) (string, bool) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, cookieMAC(key, name, string(decoded))) {
+		return "", false
+	}
+
+	return string(decoded), true
+}
+
+func newCookieAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(deriveCookieKey(key, "bottleneck encrypted cookie"))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func encryptCookieValue(key []byte, name, value string) (string, error) {
+	aead, err := newCookieAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func decryptCookieValue(key []byte, name, value string) (string, bool) {
+	aead, err := newCookieAEAD(key)
+	if err != nil {
+		return "", false
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", false
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", false
+	}
+
+	return string(plaintext), true
+}
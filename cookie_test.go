@@ -0,0 +1,125 @@
+package bottleneck
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cookieTestContext(router *Router, cookies ...*http.Cookie) (*Context, *httptest.ResponseRecorder) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+		req      = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	ctx.init(recorder, req, nil)
+	ctx.router = router
+
+	return &ctx, recorder
+}
+
+func responseCookie(recorder *httptest.ResponseRecorder) *http.Cookie {
+	return recorder.Result().Cookies()[0]
+}
+
+func TestContextSetCookie(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	ctx, recorder := cookieTestContext(router)
+	ctx.SetCookie(&http.Cookie{Name: "theme", Value: "dark"})
+
+	assert.Equal(t, "theme=dark; Path=/; Secure; SameSite=Lax", recorder.Header().Get("Set-Cookie"))
+
+	router.Cookies.Insecure = true
+	router.Cookies.SameSite = http.SameSiteStrictMode
+
+	ctx, recorder = cookieTestContext(router)
+	ctx.SetCookie(&http.Cookie{Name: "theme", Value: "dark", Path: "/app", HttpOnly: true})
+
+	assert.Equal(t, "theme=dark; Path=/app; HttpOnly; SameSite=Strict", recorder.Header().Get("Set-Cookie"))
+
+	ctx, _ = cookieTestContext(router, &http.Cookie{Name: "theme", Value: "light"})
+
+	cookie, err := ctx.Cookie("theme")
+	assert.NoError(t, err)
+	assert.Equal(t, "light", cookie.Value)
+
+	_, err = ctx.Cookie("missing")
+	assert.Equal(t, http.ErrNoCookie, err)
+}
+
+func TestContextSignedCookie(t *testing.T) {
+	var (
+		router  = NewRouter(routerTestContext{})
+		oldKey  = []byte("old-key-old-key-old-key-old-key!")
+		newKey  = []byte("new-key-new-key-new-key-new-key!")
+		written *http.Cookie
+	)
+
+	router.Cookies.Keys = [][]byte{oldKey}
+
+	ctx, recorder := cookieTestContext(router)
+	assert.NoError(t, ctx.SetSignedCookie(&http.Cookie{Name: "user", Value: "jake"}))
+	written = responseCookie(recorder)
+
+	// rotated keys still accept cookies of the old key
+	router.Cookies.Keys = [][]byte{newKey, oldKey}
+
+	ctx, _ = cookieTestContext(router, written)
+	cookie, err := ctx.SignedCookie("user")
+	assert.NoError(t, err)
+	assert.Equal(t, "jake", cookie.Value)
+
+	for _, tampered := range []*http.Cookie{
+		{Name: "user", Value: "YWRtaW4." + written.Value[5:]},
+		{Name: "user", Value: "jake"},
+		{Name: "other", Value: written.Value},
+	} {
+		ctx, _ = cookieTestContext(router, tampered)
+		_, err = ctx.SignedCookie(tampered.Name)
+		assert.True(t, errors.Is(err, ErrInvalidCookie), tampered.String())
+	}
+
+	router.Cookies.Keys = [][]byte{newKey}
+
+	ctx, _ = cookieTestContext(router, written)
+	_, err = ctx.SignedCookie("user")
+	assert.True(t, errors.Is(err, ErrInvalidCookie))
+	assert.Equal(t, http.StatusBadRequest, err.(*Error).Status)
+}
+
+func TestContextEncryptedCookie(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	ctx, _ := cookieTestContext(router)
+	assert.Equal(t, ErrNoCookieKeys, ctx.SetEncryptedCookie(&http.Cookie{Name: "session", Value: "secret"}))
+
+	router.Cookies.Keys = [][]byte{[]byte("key-key-key-key-key-key-key-key!")}
+
+	ctx, recorder := cookieTestContext(router)
+	assert.NoError(t, ctx.SetEncryptedCookie(&http.Cookie{Name: "session", Value: "secret", HttpOnly: true}))
+
+	written := responseCookie(recorder)
+	assert.NotContains(t, written.Value, "secret")
+	assert.True(t, written.HttpOnly)
+
+	ctx, _ = cookieTestContext(router, written)
+	cookie, err := ctx.EncryptedCookie("session")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", cookie.Value)
+
+	tampered := *written
+	tampered.Value = written.Value[:len(written.Value)-2] + "AA"
+
+	ctx, _ = cookieTestContext(router, &tampered)
+	_, err = ctx.EncryptedCookie("session")
+	assert.True(t, errors.Is(err, ErrInvalidCookie))
+}
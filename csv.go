@@ -0,0 +1,101 @@
+package bottleneck
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrUnsupportedCSV indicates that a value passed to the CSVRenderer is neither [][]string nor a slice of structs.
+var ErrUnsupportedCSV = errors.New("csv requires [][]string or a slice of structs")
+
+// csvRecords converts a [][]string or a slice of structs into csv records. The first record of a slice of structs
+// is the header.
+func csvRecords(value interface{}) ([][]string, error) {
+	if records, ok := value.([][]string); ok {
+		return records, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, ErrUnsupportedCSV
+	}
+
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return nil, ErrUnsupportedCSV
+	}
+
+	var (
+		indices []int
+		header  []string
+	)
+
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		indices = append(indices, i)
+		header = append(header, name)
+	}
+
+	records := make([][]string, 0, v.Len()+1)
+	records = append(records, header)
+
+	for i := 0; i < v.Len(); i++ {
+		elem := reflect.Indirect(v.Index(i))
+		record := make([]string, len(indices))
+
+		if elem.IsValid() {
+			for j, index := range indices {
+				cell, err := csvCell(elem.Field(index))
+				if err != nil {
+					return nil, err
+				}
+
+				record[j] = cell
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func csvCell(v reflect.Value) (string, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "", nil
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339), nil
+
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		return string(text), err
+
+	case fmt.Stringer:
+		return value.String(), nil
+	}
+
+	return fmt.Sprint(reflect.Indirect(v).Interface()), nil
+}
@@ -1,15 +1,18 @@
 package bottleneck
 
 import (
+	"encoding/xml"
 	"fmt"
 	"net/http"
 )
 
 // Error is a user displayable error that is returned during request handling.
 type Error struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-	Cause   error  `json:"-"`
+	XMLName   xml.Name `json:"-" xml:"error" yaml:"-"`
+	Status    int      `json:"status" xml:"status" yaml:"status"`
+	Message   string   `json:"message" xml:"message" yaml:"message"`
+	RequestID string   `json:"requestId,omitempty" xml:"requestId,omitempty" yaml:"requestId,omitempty"`
+	Cause     error    `json:"-" xml:"-" yaml:"-"`
 }
 
 // NewError creates a new Error and sets the http status code, which will be set when not handeled manually.
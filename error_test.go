@@ -1,6 +1,7 @@
 package bottleneck
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"net/http"
@@ -36,3 +37,23 @@ func TestErrorInterface(t *testing.T) {
 
 	assert.Equal(t, "status=410 message=Gone (caused by: EOF)", err.Error())
 }
+
+type errorTestCause struct {
+	Query string
+}
+
+func (e errorTestCause) Error() string {
+	return "query failed: " + e.Query
+}
+
+func TestErrorYAML(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		err = NewError(http.StatusInternalServerError).WithCause(errorTestCause{Query: "select password"})
+	)
+
+	err.RequestID = "42"
+
+	assert.NoError(t, YAMLRenderer{Value: err}.Render(&buf))
+	assert.Equal(t, "status: 500\nmessage: Internal Server Error\nrequestId: \"42\"\n", buf.String())
+}
@@ -2,9 +2,14 @@ package bottleneck
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path"
+	"strings"
+	"time"
 )
 
 // FileHandlerOptions define how static files are handled.
@@ -46,6 +51,128 @@ func serveFile(opts *FileHandlerOptions, ctx *Context, filepath string, handleNo
 		return serveFile(opts, ctx, path.Join(filepath, "index.html"), handleNotFound)
 	}
 
+	setETag(ctx.Response().Header(), info.Size(), info.ModTime())
 	http.ServeContent(ctx.Response(), ctx.Request(), info.Name(), info.ModTime(), f)
 	return nil
 }
+
+// File sends a file of fs with support for Range requests and conditional requests. Directories are answered with
+// their "index.html". A 404 *Error is returned, if the file does not exist.
+//
+//   return ctx.File(http.Dir("uploads"), ctx.Param("name"))
+func (c *Context) File(fs http.FileSystem, name string) error {
+	return serveFile(&FileHandlerOptions{Fs: fs}, c, name, false)
+}
+
+// Attachment sends content as a file, that browsers offer to save with the filename. See Inline for details.
+//
+//   return ctx.Attachment("report.pdf", bytes.NewReader(pdf), report.CreatedAt)
+func (c *Context) Attachment(filename string, content io.Reader, modTime time.Time) error {
+	return c.sendContent("attachment", filename, content, modTime)
+}
+
+// Inline sends content as a file, that browsers display if possible. The Content-Type is derived from the extension
+// of the filename or the content.
+//
+// If content is an io.ReadSeeker, Range requests are supported. A non-zero modTime enables conditional requests
+// using Last-Modified and a weak ETag, unless an ETag header is already set.
+func (c *Context) Inline(filename string, content io.Reader, modTime time.Time) error {
+	return c.sendContent("inline", filename, content, modTime)
+}
+
+func (c *Context) sendContent(disposition, filename string, content io.Reader, modTime time.Time) error {
+	header := c.Response().Header()
+	header.Set(HeaderContentDisposition, contentDisposition(disposition, filename))
+
+	seeker, ok := content.(io.ReadSeeker)
+	if !ok {
+		if header.Get(HeaderContentType) == "" {
+			header.Set(HeaderContentType, contentTypeByName(filename))
+		}
+
+		c.Response().WriteHeader(http.StatusOK)
+		_, err := io.Copy(c.Response(), content)
+		return err
+	}
+
+	if !modTime.IsZero() {
+		size, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return NewError(http.StatusInternalServerError).WithCause(err)
+		}
+
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return NewError(http.StatusInternalServerError).WithCause(err)
+		}
+
+		setETag(header, size, modTime)
+	}
+
+	http.ServeContent(c.Response(), c.Request(), filename, modTime, seeker)
+	return nil
+}
+
+// setETag sets a weak ETag derived from size and modification time, unless one is already set.
+func setETag(header http.Header, size int64, modTime time.Time) {
+	if header.Get(HeaderETag) == "" && !modTime.IsZero() {
+		header.Set(HeaderETag, fmt.Sprintf(`W/"%x-%x"`, size, modTime.UnixNano()))
+	}
+}
+
+func contentTypeByName(filename string) string {
+	if contentType := mime.TypeByExtension(path.Ext(filename)); contentType != "" {
+		return contentType
+	}
+
+	return MIMEOctetStream
+}
+
+// contentDisposition formats a Content-Disposition header. Filenames with characters other than printable ASCII,
+// quotes or backslashes are encoded with the filename* parameter and an ASCII fallback is added for old clients.
+//
+// See https://tools.ietf.org/html/rfc6266
+func contentDisposition(disposition, filename string) string {
+	var (
+		fallback strings.Builder
+		ascii    = true
+	)
+
+	for _, r := range filename {
+		// Quotes and backslashes are replaced, because clients do not handle escapes consistently.
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			ascii = false
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+
+	value := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback.String())
+
+	if !ascii {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+
+	return value
+}
+
+// encodeRFC5987 percent-encodes all bytes except the attr-chars of RFC 5987.
+func encodeRFC5987(s string) string {
+	const attrChars = "!#$&+-.^_`|~"
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		isAlnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+
+		if isAlnum || strings.IndexByte(attrChars, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -3,13 +3,17 @@ package bottleneck
 import (
 	"bytes"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -156,3 +160,99 @@ func (s *FileHandlerTestSuite) TestFsError() {
 func TestFileHandler(t *testing.T) {
 	suite.Run(t, new(FileHandlerTestSuite))
 }
+
+func TestContentDisposition(t *testing.T) {
+	for filename, expected := range map[string]string{
+		"report.pdf":       `attachment; filename="report.pdf"`,
+		"Grüße €.txt":      `attachment; filename="Gr__e _.txt"; filename*=UTF-8''Gr%C3%BC%C3%9Fe%20%E2%82%AC.txt`,
+		`say "hi".txt`:     `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`,
+		"semi;colon's.csv": `attachment; filename="semi;colon's.csv"`,
+	} {
+		assert.Equal(t, expected, contentDisposition("attachment", filename))
+	}
+}
+
+func TestContextAttachment(t *testing.T) {
+	var (
+		router  = NewRouter(routerTestContext{})
+		group   = NewGroup()
+		modTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	group.GET("/report", func(ctx *Context) error {
+		return ctx.Attachment("Bericht.txt", strings.NewReader("Hello World"), modTime)
+	})
+	group.GET("/inline", func(ctx *Context) error {
+		return ctx.Inline("page.html", io.MultiReader(strings.NewReader("<p>hi</p>")), time.Time{})
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `attachment; filename="Bericht.txt"`, res.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, "text/plain; charset=utf-8", res.Header().Get(HeaderContentType))
+	assert.Equal(t, "Hello World", res.Body.String())
+
+	etag := res.Header().Get(HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set("Range", "bytes=6-")
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusPartialContent, res.Code)
+	assert.Equal(t, "World", res.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set("If-None-Match", etag)
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotModified, res.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotModified, res.Code)
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/inline", nil))
+
+	assert.Equal(t, `inline; filename="page.html"`, res.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, "text/html; charset=utf-8", res.Header().Get(HeaderContentType))
+	assert.Equal(t, "<p>hi</p>", res.Body.String())
+}
+
+func TestContextFile(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+		fs     = afero.NewMemMapFs()
+	)
+
+	assert.NoError(t, afero.WriteFile(fs, "/docs/a.txt", []byte("file a"), 0600))
+
+	group.GET("/download/:name", func(ctx *Context) error {
+		return ctx.File(afero.NewHttpFs(fs), "/docs/"+ctx.Param("name"))
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/download/a.txt", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "file a", res.Body.String())
+	assert.NotEmpty(t, res.Header().Get(HeaderETag))
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/download/b.txt", nil))
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
@@ -2,11 +2,12 @@ package bottleneck
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/dimfeld/httptreemux/v5"
 )
 
-func makeMuxHandler(router *Router, r route) httptreemux.HandlerFunc {
+func makeMuxHandler(router *Router, r route, constraints map[string]constraint, notFound http.HandlerFunc) httptreemux.HandlerFunc {
 	var (
 		handler    = wrapHandler(router, r.handler)
 		middleware = wrapMiddlewareList(router, r.middleware)
@@ -14,14 +15,59 @@ func makeMuxHandler(router *Router, r route) httptreemux.HandlerFunc {
 	)
 
 	return func(res http.ResponseWriter, req *http.Request, params map[string]string) {
-		ctx := router.contextCreator.create(res, req, params)
+		params = withHostParams(req, params)
 
-		if err := chain(ctx, req); err != nil {
+		if !matchConstraints(constraints, params) {
+			notFound(res, req)
+			return
+		}
+
+		if req.Method == http.MethodHead && r.method == http.MethodGet {
+			head := &headResponse{ResponseWriter: res}
+			defer head.finalize()
+			res = head
+		}
+
+		ctx := router.newContext(res, req, params)
+		defer ctx.baseContext.release()
+
+		if err := chain(ctx); err != nil {
 			handleError(ctx.baseContext, err)
 		}
 	}
 }
 
+// A headResponse is used to answer HEAD requests with GET handlers. The body is discarded, but counted to send the
+// Content-Length the GET request would have had.
+type headResponse struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (h *headResponse) WriteHeader(status int) {
+	if h.status == 0 {
+		h.status = status
+	}
+}
+
+func (h *headResponse) Write(b []byte) (int, error) {
+	h.size += len(b)
+	return len(b), nil
+}
+
+func (h *headResponse) finalize() {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+
+	if header := h.Header(); header.Get(HeaderContentLength) == "" && h.size > 0 {
+		header.Set(HeaderContentLength, strconv.Itoa(h.size))
+	}
+
+	h.ResponseWriter.WriteHeader(h.status)
+}
+
 func makeChain(middleware []wrappedMiddleware, handler wrappedHandler) wrappedHandler {
 	if len(middleware) == 0 {
 		return handler
@@ -32,18 +78,35 @@ func makeChain(middleware []wrappedMiddleware, handler wrappedHandler) wrappedHa
 		next = makeChain(middleware[1:], handler)
 	)
 
-	return func(ctx *contextHolder, req *http.Request) error {
+	return func(ctx *contextHolder) error {
 		return head(ctx, func() error {
-			return next(ctx, req)
+			return next(ctx)
 		})
 	}
 }
 
 func handleError(ctx *Context, err error) {
+	if ctx.Response().Committed() {
+		// The response has already been (partially) sent, so there is no way to display the error anymore.
+		return
+	}
+
 	if err, ok := err.(*Error); ok {
-		// Ignore error here because it is just the last attempt to display it to the client.
-		// nolint:errcheck
-		ctx.JSON(err.Status, err)
+		if id := ctx.RequestID(); id != "" && err.RequestID == "" {
+			// Copy the error, because it may be shared between requests.
+			withID := *err
+			withID.RequestID = id
+			err = &withID
+		}
+
+		ctx.Response().Header().Add(HeaderVary, HeaderAccept)
+
+		negotiation := ctx.negotiation()
+		if rerr := ctx.renderBuffered(err.Status, negotiation.errorRenderer(ctx.Request(), err)); rerr != nil {
+			// Ignore error here because it is just the last attempt to display it to the client.
+			// nolint:errcheck
+			ctx.Render(err.Status, negotiation.defaultRenderer(err))
+		}
 	} else {
 		handleError(ctx, NewError(http.StatusInternalServerError).WithCause(err))
 	}
@@ -0,0 +1,78 @@
+package bottleneck
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// hostParamsKey is the key of the host parameters in the request context.
+type hostParamsKey struct{}
+
+// A hostTree holds the routes of a host pattern.
+type hostTree struct {
+	pattern string
+	labels  []string
+	tree    *tree
+}
+
+// parseHostPattern splits a host pattern into its lower case labels.
+func parseHostPattern(pattern string) []string {
+	return strings.Split(strings.ToLower(pattern), ".")
+}
+
+// match tests if a host matches the pattern and returns the values of its parameters.
+func (h *hostTree) match(host string) (map[string]string, bool) {
+	labels := strings.Split(strings.ToLower(stripPort(host)), ".")
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+
+	var params map[string]string
+
+	for i, label := range h.labels {
+		if strings.HasPrefix(label, ":") {
+			if labels[i] == "" {
+				return nil, false
+			}
+
+			if params == nil {
+				params = make(map[string]string)
+			}
+
+			params[label[1:]] = labels[i]
+		} else if label != labels[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// withHostParams merges the parameters of a matched host pattern with the path parameters.
+func withHostParams(req *http.Request, params map[string]string) map[string]string {
+	hostParams, ok := req.Context().Value(hostParamsKey{}).(map[string]string)
+	if !ok {
+		return params
+	}
+
+	merged := make(map[string]string, len(hostParams)+len(params))
+
+	for key, value := range hostParams {
+		merged[key] = value
+	}
+
+	for key, value := range params {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return host
+}
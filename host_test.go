@@ -0,0 +1,65 @@
+package bottleneck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostTreeMatch(t *testing.T) {
+	host := hostTree{labels: parseHostPattern(":tenant.Example.com")}
+
+	params, ok := host.match("acme.example.com:8080")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, params)
+
+	_, ok = host.match("example.com")
+	assert.False(t, ok)
+
+	_, ok = host.match("acme.example.org")
+	assert.False(t, ok)
+}
+
+func TestRouterMountHost(t *testing.T) {
+	var (
+		router  = NewRouter(routerTestContext{})
+		api     = NewGroup()
+		tenants = NewGroup()
+		def     = NewGroup()
+	)
+
+	api.GET("/", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "api")
+	})
+	tenants.GET("/users/:id", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, ctx.Param("tenant")+" "+ctx.Param("id"))
+	})
+	def.GET("/", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "default")
+	})
+
+	router.MountHost("api.example.com", api)
+	router.MountHost(":tenant.example.com", tenants)
+	router.Mount(def)
+
+	for expected, target := range map[string][2]string{
+		"api":                  {"api.example.com", "/"},
+		"acme 42":              {"acme.example.com", "/users/42"},
+		"default":              {"localhost:8080", "/"},
+		"404 page not found\n": {"acme.example.com", "/"},
+	} {
+		var (
+			req = httptest.NewRequest(http.MethodGet, target[1], nil)
+			res = httptest.NewRecorder()
+		)
+
+		req.Host = target[0]
+		router.ServeHTTP(res, req)
+		assert.Equal(t, expected, res.Body.String(), target)
+	}
+
+	assert.Equal(t, "api.example.com", router.Routes()[0].Host)
+	assert.Equal(t, "", router.Routes()[2].Host)
+}
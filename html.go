@@ -0,0 +1,404 @@
+package bottleneck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template/parse"
+	"time"
+)
+
+var (
+	// ErrTemplateNotFound indicates that a page passed to Context.HTML does not exist.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrNoTemplates indicates that Context.HTML is used before Router.LoadTemplates.
+	ErrNoTemplates = errors.New("no templates loaded")
+	// ErrMissingTemplate indicates that a template is referenced, but not defined.
+	ErrMissingTemplate = errors.New("missing template")
+)
+
+// HTMLRenderer implements the Renderer interface.
+type HTMLRenderer struct {
+	Template *template.Template
+	// Name is the template to execute. If it is empty, Template itself is executed.
+	Name string
+	Data interface{}
+}
+
+// Header sets the Content-Type to "text/html; charset=UTF8".
+func (HTMLRenderer) Header(h http.Header) {
+	h.Add(HeaderContentType, MIMETextHTMLCharsetUTF8)
+}
+
+// Render executes the template with Data and writes the result to w.
+func (r HTMLRenderer) Render(w io.Writer) error {
+	if r.Name == "" {
+		return r.Template.Execute(w, r.Data)
+	}
+
+	return r.Template.ExecuteTemplate(w, r.Name, r.Data)
+}
+
+// HTMLOptions define how html templates are loaded.
+//
+// Every file with the Extension, that is neither the Layout nor inside the Partials directory, is a page. Pages are
+// named by their path relative to the root of Fs, e.g. "users/show.html". Layout and partials are parsed together
+// with every page. The Layout is executed instead of the page and includes it with {{template "content" .}}, when
+// the page defines "content".
+//
+//   <!-- layout.html -->
+//   <html><body>{{template "partials/nav.html" .}}{{template "content" .}}</body></html>
+//
+//   <!-- users/show.html -->
+//   {{define "content"}}<a href="{{url "user" "id" .ID}}">{{.Name}}</a>{{end}}
+type HTMLOptions struct {
+	// Fs is used to resolve templates. An afero.Fs can be used with afero.NewHttpFs.
+	Fs http.FileSystem
+	// Extension of template files. The default is ".html".
+	Extension string
+	// Layout is the filename of an optional layout, that wraps every page.
+	Layout string
+	// Partials is a directory of templates, that are available in every page. The default is "partials".
+	Partials string
+	// Funcs are added to the default functions of every template.
+	Funcs template.FuncMap
+	// Dev re-parses the templates whenever a file changed. Otherwise templates are parsed once.
+	Dev bool
+}
+
+func (o *HTMLOptions) defaults() {
+	if o.Extension == "" {
+		o.Extension = ".html"
+	}
+
+	if o.Partials == "" {
+		o.Partials = "partials"
+	}
+
+	o.Layout = strings.TrimPrefix(o.Layout, "/")
+	o.Partials = strings.Trim(o.Partials, "/")
+}
+
+// LoadTemplates parses the html templates used by Context.HTML. References to templates, that are not defined, are
+// reported as errors, so they are noticed at startup instead of at the first request.
+//
+// Besides HTMLOptions.Funcs the template function "url" is available, which returns the path of a named route. See
+// Router.URL.
+//
+//   if err := router.LoadTemplates(bottleneck.HTMLOptions{
+//     Fs:     http.Dir("templates"),
+//     Layout: "layout.html",
+//     Dev:    os.Getenv("DEV") != "",
+//   }); err != nil {
+//     log.Fatal(err)
+//   }
+func (r *Router) LoadTemplates(opts HTMLOptions) error {
+	opts.defaults()
+
+	funcs := template.FuncMap{"url": r.templateURL}
+	for name, fn := range opts.Funcs {
+		funcs[name] = fn
+	}
+
+	templates := &htmlTemplates{opts: opts, funcs: funcs}
+	if err := templates.load(); err != nil {
+		return err
+	}
+
+	r.templates = templates
+	return nil
+}
+
+// templateURL is Router.URL with param values of any type, which are formatted with fmt.Sprint.
+func (r *Router) templateURL(name string, params ...interface{}) (string, error) {
+	strs := make([]string, len(params))
+	for i, param := range params {
+		strs[i] = fmt.Sprint(param)
+	}
+
+	return r.URL(name, strs...)
+}
+
+// HTML executes a page loaded with Router.LoadTemplates and writes it as "text/html". The page is executed into a
+// buffer first, so that template errors are reported through the regular error handling.
+//
+//   router.GET("/users/:id", func(ctx *Context) error {
+//     return ctx.HTML(http.StatusOK, "users/show.html", user)
+//   })
+func (c *Context) HTML(status int, name string, data interface{}) error {
+	if c.router == nil || c.router.templates == nil {
+		return NewError(http.StatusInternalServerError).WithCause(ErrNoTemplates)
+	}
+
+	var buf bytes.Buffer
+
+	if err := c.router.templates.execute(&buf, name, data); err != nil {
+		return NewError(http.StatusInternalServerError).WithCause(err)
+	}
+
+	return c.Render(status, StreamRenderer{
+		ContentType: MIMETextHTMLCharsetUTF8,
+		Reader:      &buf,
+	})
+}
+
+type htmlPage struct {
+	template *template.Template
+	entry    string
+}
+
+type htmlTemplates struct {
+	opts  HTMLOptions
+	funcs template.FuncMap
+
+	mutex    sync.RWMutex
+	pages    map[string]htmlPage
+	modTimes map[string]time.Time
+}
+
+func (t *htmlTemplates) execute(w io.Writer, name string, data interface{}) error {
+	if t.opts.Dev {
+		if err := t.reloadIfChanged(); err != nil {
+			return err
+		}
+	}
+
+	t.mutex.RLock()
+	page, ok := t.pages[strings.TrimPrefix(name, "/")]
+	t.mutex.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	return HTMLRenderer{Template: page.template, Name: page.entry, Data: data}.Render(w)
+}
+
+func (t *htmlTemplates) reloadIfChanged() error {
+	modTimes, err := t.scan()
+	if err != nil {
+		return err
+	}
+
+	t.mutex.RLock()
+	changed := !equalModTimes(modTimes, t.modTimes)
+	t.mutex.RUnlock()
+
+	if changed {
+		return t.load()
+	}
+
+	return nil
+}
+
+// load parses every page together with the layout and partials and checks all template references.
+func (t *htmlTemplates) load() error {
+	modTimes, err := t.scan()
+	if err != nil {
+		return err
+	}
+
+	var (
+		layout   string
+		partials []string
+		pages    = make(map[string]htmlPage)
+	)
+
+	for name := range modTimes {
+		switch {
+		case name == t.opts.Layout:
+			layout = name
+		case strings.HasPrefix(name, t.opts.Partials+"/"):
+			partials = append(partials, name)
+		}
+	}
+
+	if t.opts.Layout != "" && layout == "" {
+		return fmt.Errorf("%w: layout %s", ErrMissingTemplate, t.opts.Layout)
+	}
+
+	sort.Strings(partials)
+
+	for name := range modTimes {
+		if name == layout || strings.HasPrefix(name, t.opts.Partials+"/") {
+			continue
+		}
+
+		page, err := t.parsePage(name, layout, partials)
+		if err != nil {
+			return err
+		}
+
+		pages[name] = page
+	}
+
+	t.mutex.Lock()
+	t.pages = pages
+	t.modTimes = modTimes
+	t.mutex.Unlock()
+
+	return nil
+}
+
+func (t *htmlTemplates) parsePage(name, layout string, partials []string) (htmlPage, error) {
+	page := htmlPage{
+		template: template.New(name).Funcs(t.funcs),
+		entry:    name,
+	}
+
+	for _, filename := range append([]string{name}, partials...) {
+		if err := t.parse(page.template, name, filename); err != nil {
+			return page, err
+		}
+	}
+
+	// Pages without "content" are not wrapped in the layout.
+	if layout != "" && page.template.Lookup("content") != nil {
+		if err := t.parse(page.template, name, layout); err != nil {
+			return page, err
+		}
+
+		page.entry = layout
+	}
+
+	if err := checkReferences(name, page.template); err != nil {
+		return page, err
+	}
+
+	return page, nil
+}
+
+func (t *htmlTemplates) parse(set *template.Template, page, filename string) error {
+	src, err := t.read(filename)
+	if err != nil {
+		return err
+	}
+
+	tmpl := set
+	if filename != page {
+		tmpl = set.New(filename)
+	}
+
+	_, err = tmpl.Parse(src)
+	return err
+}
+
+func (t *htmlTemplates) read(filename string) (string, error) {
+	f, err := t.opts.Fs.Open("/" + filename)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	return string(b), err
+}
+
+// scan returns the modification times of all template files by name.
+func (t *htmlTemplates) scan() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	return modTimes, t.walk("", modTimes)
+}
+
+func (t *htmlTemplates) walk(dir string, modTimes map[string]time.Time) error {
+	f, err := t.opts.Fs.Open("/" + dir)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		name := path.Join(dir, info.Name())
+
+		switch {
+		case info.IsDir():
+			if err := t.walk(name, modTimes); err != nil {
+				return err
+			}
+
+		case path.Ext(name) == t.opts.Extension:
+			modTimes[name] = info.ModTime()
+		}
+	}
+
+	return nil
+}
+
+func equalModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, modTime := range a {
+		if other, ok := b[name]; !ok || !other.Equal(modTime) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkReferences reports template actions, that reference templates not defined in the set.
+func checkReferences(page string, set *template.Template) error {
+	var missing []string
+
+	for _, tmpl := range set.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+
+		walkTemplateNodes(tmpl.Tree.Root, func(name string) {
+			if set.Lookup(name) == nil {
+				missing = append(missing, name)
+			}
+		})
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %s references %s", ErrMissingTemplate, page, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func walkTemplateNodes(node parse.Node, fn func(name string)) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node != nil {
+			for _, child := range node.Nodes {
+				walkTemplateNodes(child, fn)
+			}
+		}
+
+	case *parse.IfNode:
+		walkTemplateNodes(node.List, fn)
+		walkTemplateNodes(node.ElseList, fn)
+
+	case *parse.RangeNode:
+		walkTemplateNodes(node.List, fn)
+		walkTemplateNodes(node.ElseList, fn)
+
+	case *parse.WithNode:
+		walkTemplateNodes(node.List, fn)
+		walkTemplateNodes(node.ElseList, fn)
+
+	case *parse.TemplateNode:
+		fn(node.Name)
+	}
+}
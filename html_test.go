@@ -0,0 +1,144 @@
+package bottleneck
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type htmlTestSuite struct {
+	suite.Suite
+
+	fs     afero.Fs
+	router *Router
+}
+
+func TestHTML(t *testing.T) {
+	suite.Run(t, new(htmlTestSuite))
+}
+
+func (s *htmlTestSuite) SetupTest() {
+	s.fs = afero.NewMemMapFs()
+
+	s.writeFile("layout.html", `<main>{{template "partials/nav.html" .}}{{template "content" .}}</main>`)
+	s.writeFile("partials/nav.html", `<nav>{{title .Name}}</nav>`)
+	s.writeFile("users/show.html", `{{define "content"}}<a href="{{url "user" "id" .ID}}">{{.Name}}</a>{{end}}`)
+	s.writeFile("plain.html", `<p>{{.Name}}</p>`)
+	s.writeFile("README.md", `{{template "ignored"}}`)
+
+	s.router = NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.GET("/users/:id<int>", func(ctx *Context) error {
+		return ctx.HTML(http.StatusOK, "users/show.html", map[string]interface{}{"ID": 42, "Name": "<jake>"})
+	}).Name("user")
+	group.GET("/plain", func(ctx *Context) error {
+		return ctx.HTML(http.StatusOK, "plain.html", map[string]interface{}{"Name": "jake"})
+	})
+	group.GET("/missing", func(ctx *Context) error {
+		return ctx.HTML(http.StatusOK, "missing.html", nil)
+	})
+
+	s.router.Mount(group)
+}
+
+func (s *htmlTestSuite) writeFile(filename, content string) {
+	filename = "/" + filename
+
+	s.Require().NoError(s.fs.MkdirAll(path.Dir(filename), 0700))
+	s.Require().NoError(afero.WriteFile(s.fs, filename, []byte(content), 0600))
+}
+
+func (s *htmlTestSuite) load(dev bool) error {
+	return s.router.LoadTemplates(HTMLOptions{
+		Fs:     afero.NewHttpFs(s.fs),
+		Layout: "layout.html",
+		Funcs: map[string]interface{}{
+			"title": func(s string) string { return "Hello " + s },
+		},
+		Dev: dev,
+	})
+}
+
+func (s *htmlTestSuite) get(target string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	s.router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, target, nil))
+	return res
+}
+
+func (s *htmlTestSuite) TestLayout() {
+	s.Require().NoError(s.load(false))
+
+	res := s.get("/users/42")
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(MIMETextHTMLCharsetUTF8, res.Header().Get(HeaderContentType))
+	s.Equal(`<main><nav>Hello &lt;jake&gt;</nav><a href="/users/42">&lt;jake&gt;</a></main>`, res.Body.String())
+
+	res = s.get("/plain")
+	s.Equal(`<p>jake</p>`, res.Body.String())
+}
+
+func (s *htmlTestSuite) TestNotFound() {
+	s.Require().NoError(s.load(false))
+
+	res := s.get("/missing")
+	s.Equal(http.StatusInternalServerError, res.Code)
+}
+
+func (s *htmlTestSuite) TestMissingReference() {
+	s.writeFile("broken.html", `{{define "content"}}{{template "partials/footer.html"}}{{end}}`)
+
+	err := s.load(false)
+	s.True(errors.Is(err, ErrMissingTemplate))
+	s.Contains(err.Error(), "broken.html references partials/footer.html")
+}
+
+func (s *htmlTestSuite) TestDevReload() {
+	s.Require().NoError(s.load(true))
+	s.Equal(`<p>jake</p>`, s.get("/plain").Body.String())
+
+	s.writeFile("plain.html", `<div>{{.Name}}</div>`)
+	s.Require().NoError(s.fs.Chtimes("/plain.html", time.Now(), time.Now().Add(time.Second)))
+
+	s.Equal(`<div>jake</div>`, s.get("/plain").Body.String())
+}
+
+func (s *htmlTestSuite) TestNoReloadInProduction() {
+	s.Require().NoError(s.load(false))
+
+	s.writeFile("plain.html", `<div>{{.Name}}</div>`)
+	s.Require().NoError(s.fs.Chtimes("/plain.html", time.Now(), time.Now().Add(time.Second)))
+
+	s.Equal(`<p>jake</p>`, s.get("/plain").Body.String())
+}
+
+func TestRouterURL(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup().WithPrefix("/api")
+	group.GET("/users/:id<int>/posts/:slug", func(*Context) error { return nil }).Name("post")
+	group.GET("/files/*path", func(*Context) error { return nil }).Name("file")
+
+	router.Mount(NewGroup().Mount(group))
+
+	url, err := router.URL("post", "id", "42", "slug", "a b")
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/users/42/posts/a%20b", url)
+
+	url, err = router.URL("file", "path", "docs/read me.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/files/docs/read%20me.txt", url)
+
+	_, err = router.URL("post", "id", "42")
+	assert.True(t, errors.Is(err, ErrInvalidURL))
+
+	_, err = router.URL("unknown")
+	assert.True(t, errors.Is(err, ErrInvalidURL))
+}
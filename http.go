@@ -6,25 +6,63 @@ const (
 
 // Some well-known http header keys.
 const (
-	HeaderAcceptEncoding  = "Accept-Encoding"
-	HeaderContentEncoding = "Content-Encoding"
-	HeaderContentType     = "Content-Type"
-	HeaderVary            = "Vary"
+	HeaderAccept                        = "Accept"
+	HeaderAcceptEncoding                = "Accept-Encoding"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAllow                         = "Allow"
+	HeaderCacheControl                  = "Cache-Control"
+	HeaderContentDisposition            = "Content-Disposition"
+	HeaderContentEncoding               = "Content-Encoding"
+	HeaderContentLength                 = "Content-Length"
+	HeaderContentType                   = "Content-Type"
+	HeaderETag                          = "ETag"
+	HeaderForwarded                     = "Forwarded"
+	HeaderLastEventID                   = "Last-Event-ID"
+	HeaderOrigin                        = "Origin"
+	HeaderRateLimitLimit                = "RateLimit-Limit"
+	HeaderRateLimitRemaining            = "RateLimit-Remaining"
+	HeaderRateLimitReset                = "RateLimit-Reset"
+	HeaderReferer                       = "Referer"
+	HeaderRetryAfter                    = "Retry-After"
+	HeaderVary                          = "Vary"
+	HeaderXForwardedFor                 = "X-Forwarded-For"
+	HeaderXForwardedHost                = "X-Forwarded-Host"
+	HeaderXForwardedProto               = "X-Forwarded-Proto"
+	HeaderXRealIP                       = "X-Real-IP"
+	HeaderXRequestID                    = "X-Request-ID"
 )
 
 // Some well-known content types.
 //
 // See https://www.iana.org/assignments/media-types/media-types.xhtml.
 const (
-	MIMEApplicationForm            = "application/x-www-form-urlencoded"
-	MIMEApplicationJSON            = "application/json"
-	MIMEApplicationJSONCharsetUTF8 = MIMEApplicationJSON + "; " + charsetUTF8
-	MIMEApplicationXML             = "application/xml"
-	MIMEApplicationXMLCharsetUTF8  = MIMEApplicationXML + "; " + charsetUTF8
-	MIMEMultipartForm              = "multipart/form-data"
-	MIMEOctetStream                = "application/octet-stream"
-	MIMETextPlain                  = "text/plain"
-	MIMETextPlainCharsetUTF8       = MIMETextPlain + "; " + charsetUTF8
-	MIMETextXML                    = "text/xml"
-	MIMETextXMLCharsetUTF8         = MIMETextXML + "; " + charsetUTF8
+	MIMEApplicationForm                  = "application/x-www-form-urlencoded"
+	MIMEApplicationJSON                  = "application/json"
+	MIMEApplicationJSONCharsetUTF8       = MIMEApplicationJSON + "; " + charsetUTF8
+	MIMEApplicationJavaScript            = "application/javascript"
+	MIMEApplicationJavaScriptCharsetUTF8 = MIMEApplicationJavaScript + "; " + charsetUTF8
+	MIMEApplicationMessagePack           = "application/msgpack"
+	MIMEApplicationNDJSON                = "application/x-ndjson"
+	MIMEApplicationXML                   = "application/xml"
+	MIMEApplicationXMLCharsetUTF8        = MIMEApplicationXML + "; " + charsetUTF8
+	MIMEApplicationYAML                  = "application/yaml"
+	MIMEApplicationYAMLCharsetUTF8       = MIMEApplicationYAML + "; " + charsetUTF8
+	MIMEMultipartForm                    = "multipart/form-data"
+	MIMEOctetStream                      = "application/octet-stream"
+	MIMETextCSV                          = "text/csv"
+	MIMETextCSVCharsetUTF8               = MIMETextCSV + "; " + charsetUTF8
+	MIMETextEventStream                  = "text/event-stream"
+	MIMETextHTML                         = "text/html"
+	MIMETextHTMLCharsetUTF8              = MIMETextHTML + "; " + charsetUTF8
+	MIMETextPlain                        = "text/plain"
+	MIMETextPlainCharsetUTF8             = MIMETextPlain + "; " + charsetUTF8
+	MIMETextXML                          = "text/xml"
+	MIMETextXMLCharsetUTF8               = MIMETextXML + "; " + charsetUTF8
 )
@@ -0,0 +1,158 @@
+package bottleneck
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// A JSONEncoder writes a value as JSON to w respecting the indentation and html escaping of the options. It can be
+// used to plug in a faster third-party library.
+type JSONEncoder interface {
+	EncodeJSON(w io.Writer, value interface{}, opts JSONOptions) error
+}
+
+// JSONEncoderFunc is an adapter to use a func as JSONEncoder.
+type JSONEncoderFunc func(w io.Writer, value interface{}, opts JSONOptions) error
+
+// EncodeJSON calls f.
+func (f JSONEncoderFunc) EncodeJSON(w io.Writer, value interface{}, opts JSONOptions) error {
+	return f(w, value, opts)
+}
+
+// JSONOptions define how the JSONRenderer encodes values.
+type JSONOptions struct {
+	// Indent is used to indent nested values. An empty Indent produces compact JSON.
+	Indent string
+	// EscapeHTML escapes the characters <, > and & inside of strings.
+	EscapeHTML bool
+	// Stream writes directly to the response instead of encoding the whole value into a buffer first. Slices are
+	// encoded one element at a time. Errors during encoding can no longer be rendered, because the header is already
+	// sent.
+	Stream bool
+	// Encoder is used to encode values. If it is nil, encoding/json is used.
+	Encoder JSONEncoder
+}
+
+// DefaultJSONOptions produce the same compact output as json.Marshal.
+var DefaultJSONOptions = JSONOptions{EscapeHTML: true}
+
+func (o JSONOptions) encoder() JSONEncoder {
+	if o.Encoder != nil {
+		return o.Encoder
+	}
+
+	return JSONEncoderFunc(encodeStdJSON)
+}
+
+func encodeStdJSON(w io.Writer, value interface{}, opts JSONOptions) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(opts.EscapeHTML)
+	encoder.SetIndent("", opts.Indent)
+	return encoder.Encode(value)
+}
+
+// encodeJSON encodes a value into a buffer and writes it without a trailing newline.
+func encodeJSON(w io.Writer, value interface{}, opts JSONOptions) error {
+	var buf bytes.Buffer
+
+	if err := opts.encoder().EncodeJSON(&buf, value, opts); err != nil {
+		return err
+	}
+
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// trimNewlineWriter holds back a trailing newline, so that values encoded directly into the underlying writer end
+// without one, just like buffered values.
+type trimNewlineWriter struct {
+	w       io.Writer
+	newline bool
+}
+
+func (t *trimNewlineWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if t.newline {
+		if _, err := t.w.Write([]byte("\n")); err != nil {
+			return 0, err
+		}
+	}
+
+	t.newline = p[len(p)-1] == '\n'
+
+	n, err := t.w.Write(bytes.TrimSuffix(p, []byte("\n")))
+	if t.newline && err == nil {
+		n++
+	}
+
+	return n, err
+}
+
+// streamJSON writes slices element by element, so that only a single element is buffered at a time. Other values
+// are encoded directly into w by the JSONEncoder of the options without a buffer.
+func streamJSON(w io.Writer, value interface{}, opts JSONOptions) error {
+	v := reflect.ValueOf(value)
+
+	streamable := (v.Kind() == reflect.Slice && !v.IsNil() || v.Kind() == reflect.Array) &&
+		v.Type().Elem().Kind() != reflect.Uint8 &&
+		!v.Type().Implements(jsonMarshalerType)
+
+	if !streamable {
+		return opts.encoder().EncodeJSON(&trimNewlineWriter{w: w}, value, opts)
+	}
+
+	var (
+		buf     bytes.Buffer
+		encoder = opts.encoder()
+		newline = []byte("\n")
+		indent  = []byte("\n" + opts.Indent)
+	)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		buf.Reset()
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if opts.Indent != "" {
+			buf.Write(indent)
+		}
+
+		start := buf.Len()
+
+		if err := encoder.EncodeJSON(&buf, v.Index(i).Interface(), opts); err != nil {
+			return err
+		}
+
+		elem := bytes.TrimSuffix(buf.Bytes(), newline)
+
+		if opts.Indent != "" {
+			// Strings never contain raw newlines, so every newline belongs to the indentation.
+			elem = append(elem[:start:start], bytes.ReplaceAll(elem[start:], newline, indent)...)
+		}
+
+		if _, err := w.Write(elem); err != nil {
+			return err
+		}
+	}
+
+	end := "]"
+	if opts.Indent != "" && v.Len() > 0 {
+		end = "\n]"
+	}
+
+	_, err := io.WriteString(w, end)
+	return err
+}
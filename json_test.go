@@ -0,0 +1,112 @@
+package bottleneck
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONRendererOptions(t *testing.T) {
+	value := []map[string]string{{"a": "<b>"}, {"c": "d"}}
+
+	for _, tc := range []struct {
+		opts     JSONOptions
+		expected string
+	}{
+		{
+			opts:     DefaultJSONOptions,
+			expected: `[{"a":"\u003cb\u003e"},{"c":"d"}]`,
+		},
+		{
+			opts:     JSONOptions{},
+			expected: `[{"a":"<b>"},{"c":"d"}]`,
+		},
+		{
+			opts:     JSONOptions{Indent: "  "},
+			expected: "[\n  {\n    \"a\": \"<b>\"\n  },\n  {\n    \"c\": \"d\"\n  }\n]",
+		},
+		{
+			opts:     JSONOptions{Stream: true},
+			expected: `[{"a":"<b>"},{"c":"d"}]`,
+		},
+		{
+			opts:     JSONOptions{Stream: true, Indent: "  "},
+			expected: "[\n  {\n    \"a\": \"<b>\"\n  },\n  {\n    \"c\": \"d\"\n  }\n]",
+		},
+	} {
+		var buf bytes.Buffer
+
+		opts := tc.opts
+		assert.NoError(t, JSONRenderer{Value: value, Options: &opts}.Render(&buf))
+		assert.Equal(t, tc.expected, buf.String(), "%+v", tc.opts)
+	}
+}
+
+func TestJSONRendererStreamValues(t *testing.T) {
+	opts := JSONOptions{Stream: true, Indent: "  "}
+
+	for value, expected := range map[interface{}]string{
+		"text":                `"text"`,
+		&[]int{}:              `[]`,
+		&[]byte{1}:            `"AQ=="`,
+		&json.RawMessage{'1'}: `1`,
+	} {
+		var buf bytes.Buffer
+
+		assert.NoError(t, JSONRenderer{Value: value, Options: &opts}.Render(&buf))
+		assert.Equal(t, expected, buf.String())
+	}
+}
+
+func TestJSONRendererEncoder(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+		router   = NewRouter(routerTestContext{})
+	)
+
+	router.JSON.Encoder = JSONEncoderFunc(func(w io.Writer, value interface{}, opts JSONOptions) error {
+		_, err := io.WriteString(w, "custom")
+		return err
+	})
+
+	ctx.init(recorder, nil, nil)
+	ctx.router = router
+
+	assert.NoError(t, ctx.JSON(http.StatusOK, nil))
+	assert.Equal(t, "custom", recorder.Body.String())
+}
+
+type jsonTestWriter struct {
+	writes []string
+}
+
+func (w *jsonTestWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestJSONRendererStreamUnbuffered(t *testing.T) {
+	var (
+		w    jsonTestWriter
+		opts = JSONOptions{Stream: true}
+	)
+
+	opts.Encoder = JSONEncoderFunc(func(w io.Writer, value interface{}, opts JSONOptions) error {
+		for _, s := range []string{"{", `"a":1`, "}\n"} {
+			if _, err := io.WriteString(w, s); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, JSONRenderer{Value: map[string]int{"a": 1}, Options: &opts}.Render(&w))
+	assert.Equal(t, []string{"{", `"a":1`, "}"}, w.writes)
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import "github.com/lukasdietrich/bottleneck"
+
+// BufferBody creates a middleware that buffers the request body with Context.BufferBody, so that middleware and
+// handlers can read it more than once, e.g. to verify a signature with Context.RawBody before binding the payload.
+//
+//   group.POST("/webhooks", handleWebhook, middleware.BufferBody(bottleneck.BufferOptions{MaxSize: 1 << 20}))
+//
+// When combined with Limit, Limit should come first, so that large bodies are rejected while they are read.
+func BufferBody(opts bottleneck.BufferOptions) StandardMiddleware {
+	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
+		if err := ctx.BufferBody(opts); err != nil {
+			return err
+		}
+
+		return next()
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/suite"
+)
+
+type BufferContext struct {
+	bottleneck.Context
+}
+
+type BufferRequest struct {
+	Value string
+}
+
+type BufferTestSuite struct {
+	suite.Suite
+	router *bottleneck.Router
+}
+
+func (s *BufferTestSuite) SetupTest() {
+	var (
+		router = bottleneck.NewRouter(BufferContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	handler := func(ctx *BufferContext, req *BufferRequest) error {
+		raw, err := ctx.RawBody()
+		if err != nil {
+			return err
+		}
+
+		b, err := ioutil.ReadAll(raw)
+		if err != nil {
+			return err
+		}
+
+		return ctx.String(http.StatusOK, req.Value+" "+string(b))
+	}
+
+	group.POST("/limit-first", handler, Limit(32), BufferBody(bottleneck.BufferOptions{}))
+	group.POST("/buffer-first", handler, BufferBody(bottleneck.BufferOptions{}), Limit(32))
+
+	router.Mount(group)
+	s.router = router
+}
+
+func (s *BufferTestSuite) serve(path, body string) *httptest.ResponseRecorder {
+	var (
+		req = httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		res = httptest.NewRecorder()
+	)
+
+	req.Header.Add(bottleneck.HeaderContentType, bottleneck.MIMEApplicationJSONCharsetUTF8)
+	s.router.ServeHTTP(res, req)
+
+	return res
+}
+
+func (s *BufferTestSuite) TestReplay() {
+	for _, path := range []string{"/limit-first", "/buffer-first"} {
+		res := s.serve(path, `{"value":"1234"}`)
+
+		s.Equal(http.StatusOK, res.Code)
+		s.Equal(`1234 {"value":"1234"}`, res.Body.String())
+	}
+}
+
+func (s *BufferTestSuite) TestLimitExceeded() {
+	for _, path := range []string{"/limit-first", "/buffer-first"} {
+		res := s.serve(path, `{"value":"`+strings.Repeat("x", 32)+`"}`)
+
+		s.Equal(http.StatusRequestEntityTooLarge, res.Code)
+	}
+}
+
+func TestBufferBody(t *testing.T) {
+	suite.Run(t, new(BufferTestSuite))
+}
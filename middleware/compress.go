@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"bufio"
 	"compress/flate"
 	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 
@@ -54,6 +56,37 @@ func (r *compressedResponse) Write(b []byte) (int, error) {
 	return r.writer.Write(b)
 }
 
+// Flush flushes the encoder and then the underlying http.ResponseWriter, so streamed data reaches the client.
+func (r *compressedResponse) Flush() {
+	if flusher, ok := r.writer.(interface{ Flush() error }); ok {
+		flusher.Flush() // nolint:errcheck
+	}
+
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack passes through to the underlying http.ResponseWriter. The connection is not compressed.
+func (r *compressedResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, bottleneck.ErrHijackNotSupported
+	}
+
+	return hijacker.Hijack()
+}
+
+// Push passes through to the underlying http.ResponseWriter.
+func (r *compressedResponse) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := r.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return pusher.Push(target, opts)
+}
+
 func (r *compressedResponse) finalize(ctx *bottleneck.Context) {
 	if r.committed {
 		if closer, ok := r.writer.(io.Closer); ok {
@@ -79,6 +112,11 @@ func Compress() StandardMiddleware {
 		if encoding := chooseEncoding(ctx.Request().Header.Get(bottleneck.HeaderAcceptEncoding)); encoding != "" {
 			res.Header().Set(bottleneck.HeaderContentEncoding, encoding)
 
+			// A Content-Length set by the handler refers to the uncompressed body.
+			res.Before(func() {
+				res.Header().Del(bottleneck.HeaderContentLength)
+			})
+
 			original := res.Writer
 			encoder, err := applyEncoding(encoding, original)
 			if err != nil {
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"compress/flate"
 	"compress/gzip"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -92,3 +94,47 @@ func (s *CompressTestSuite) TestNone() {
 func TestCompress(t *testing.T) {
 	suite.Run(t, new(CompressTestSuite))
 }
+
+func TestCompressFlush(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(CompressContext{})
+		group  = bottleneck.NewGroup()
+		req    = httptest.NewRequest(http.MethodGet, "/stream", nil)
+		res    = httptest.NewRecorder()
+	)
+
+	group.Use(Compress())
+	group.GET("/stream", func(ctx *CompressContext) error {
+		ctx.Response().Header().Set(bottleneck.HeaderContentLength, "5")
+		ctx.Response().Write([]byte("Hello"))
+		ctx.Response().Flush()
+
+		// Everything written so far must be readable by the client before the handler returns.
+		r, err := gzip.NewReader(bytes.NewReader(res.Body.Bytes()))
+		if err != nil {
+			return err
+		}
+
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+
+		return ctx.String(http.StatusOK, string(buf))
+	})
+
+	router.Mount(group)
+
+	req.Header.Add(bottleneck.HeaderAcceptEncoding, "gzip")
+	router.ServeHTTP(res, req)
+
+	r, err := gzip.NewReader(res.Body)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	assert.True(t, res.Flushed)
+	assert.Equal(t, "", res.Header().Get(bottleneck.HeaderContentLength))
+	assert.Equal(t, "HelloHello", buf.String())
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukasdietrich/bottleneck"
+)
+
+// CORSOptions define which cross-origin requests the CORS middleware allows.
+type CORSOptions struct {
+	// AllowOrigins lists the allowed origins like "https://example.com". An origin may contain a single wildcard to
+	// allow subdomains like "https://*.example.com". A single "*" allows every origin.
+	AllowOrigins []string
+	// AllowOriginFunc is called for origins, that are not matched by AllowOrigins.
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods are the methods allowed in preflight requests. The default is GET, HEAD, PUT, PATCH, POST and
+	// DELETE.
+	AllowMethods []string
+	// AllowHeaders are the request headers allowed in preflight requests. By default the requested headers are
+	// allowed.
+	AllowHeaders []string
+	// ExposeHeaders are the response headers, that scripts are allowed to read.
+	ExposeHeaders []string
+	// AllowCredentials allows cookies and authorization headers. The origin is always sent explicitly, even if every
+	// origin is allowed.
+	AllowCredentials bool
+	// MaxAge is the duration the result of a preflight request may be cached. Zero omits the header.
+	MaxAge time.Duration
+}
+
+// CORS creates a middleware that implements cross-origin resource sharing. Preflight requests are answered by the
+// middleware without calling the handler. Because automatic OPTIONS answers run through the group middleware of the
+// requested method, preflight requests are answered even if only GET or POST is registered. CORS must therefore be
+// added with Group.Use rather than for single routes. Requests from origins, that are not allowed, are passed on
+// without CORS headers, so browsers block the response.
+//
+//   group.Use(middleware.CORS(middleware.CORSOptions{
+//     AllowOrigins:     []string{"https://example.com", "https://*.example.com"},
+//     AllowCredentials: true,
+//     MaxAge:           time.Hour,
+//   }))
+//
+// See https://fetch.spec.whatwg.org/#http-cors-protocol
+func CORS(opts CORSOptions) StandardMiddleware {
+	if len(opts.AllowMethods) == 0 {
+		opts.AllowMethods = []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodPost,
+			http.MethodDelete,
+		}
+	}
+
+	var (
+		allowMethods  = strings.Join(opts.AllowMethods, ", ")
+		allowHeaders  = strings.Join(opts.AllowHeaders, ", ")
+		exposeHeaders = strings.Join(opts.ExposeHeaders, ", ")
+		maxAge        = strconv.Itoa(int(opts.MaxAge / time.Second))
+		allowAll      = false
+	)
+
+	for _, origin := range opts.AllowOrigins {
+		allowAll = allowAll || origin == "*"
+	}
+
+	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
+		var (
+			req    = ctx.Request()
+			header = ctx.Response().Header()
+			origin = req.Header.Get(bottleneck.HeaderOrigin)
+		)
+
+		header.Add(bottleneck.HeaderVary, bottleneck.HeaderOrigin)
+
+		if origin == "" || !(allowAll || allowOrigin(opts, origin)) {
+			return next()
+		}
+
+		if allowAll && !opts.AllowCredentials {
+			header.Set(bottleneck.HeaderAccessControlAllowOrigin, "*")
+		} else {
+			header.Set(bottleneck.HeaderAccessControlAllowOrigin, origin)
+		}
+
+		if opts.AllowCredentials {
+			header.Set(bottleneck.HeaderAccessControlAllowCredentials, "true")
+		}
+
+		requestMethod := req.Header.Get(bottleneck.HeaderAccessControlRequestMethod)
+
+		if req.Method != http.MethodOptions || requestMethod == "" {
+			if exposeHeaders != "" {
+				header.Set(bottleneck.HeaderAccessControlExposeHeaders, exposeHeaders)
+			}
+
+			return next()
+		}
+
+		header.Add(bottleneck.HeaderVary, bottleneck.HeaderAccessControlRequestMethod)
+		header.Add(bottleneck.HeaderVary, bottleneck.HeaderAccessControlRequestHeaders)
+		header.Set(bottleneck.HeaderAccessControlAllowMethods, allowMethods)
+
+		if allowHeaders != "" {
+			header.Set(bottleneck.HeaderAccessControlAllowHeaders, allowHeaders)
+		} else if requested := req.Header.Get(bottleneck.HeaderAccessControlRequestHeaders); requested != "" {
+			header.Set(bottleneck.HeaderAccessControlAllowHeaders, requested)
+		}
+
+		if opts.MaxAge > 0 {
+			header.Set(bottleneck.HeaderAccessControlMaxAge, maxAge)
+		}
+
+		return ctx.NoContent(http.StatusNoContent)
+	}
+}
+
+func allowOrigin(opts CORSOptions, origin string) bool {
+	for _, pattern := range opts.AllowOrigins {
+		if matchOrigin(pattern, origin) {
+			return true
+		}
+	}
+
+	return opts.AllowOriginFunc != nil && opts.AllowOriginFunc(origin)
+}
+
+// matchOrigin compares an origin to a pattern with an optional wildcard. The wildcard matches one or more subdomain
+// labels, but neither the scheme nor the port.
+func matchOrigin(pattern, origin string) bool {
+	pattern, origin = strings.ToLower(pattern), strings.ToLower(origin)
+
+	i := strings.IndexByte(pattern, '*')
+	if i < 0 {
+		return pattern == origin
+	}
+
+	var (
+		prefix = pattern[:i]
+		suffix = pattern[i+1:]
+	)
+
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+
+	wildcard := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(wildcard, "/:@") && !strings.HasPrefix(wildcard, ".")
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CORSContext struct {
+	bottleneck.Context
+}
+
+type CORSTestSuite struct {
+	suite.Suite
+	router *bottleneck.Router
+}
+
+func (s *CORSTestSuite) SetupTest() {
+	var (
+		router = bottleneck.NewRouter(CORSContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(CORS(CORSOptions{
+		AllowOrigins:  []string{"https://example.com", "https://*.example.org"},
+		ExposeHeaders: []string{"X-Total-Count"},
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".test")
+		},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	group.GET("/items", func(ctx *CORSContext) error {
+		return ctx.String(http.StatusOK, "items")
+	})
+	group.POST("/items", func(ctx *CORSContext) error {
+		return ctx.NoContent(http.StatusCreated)
+	})
+
+	router.Mount(group)
+	s.router = router
+}
+
+func (s *CORSTestSuite) serve(method, origin string, header map[string]string) *httptest.ResponseRecorder {
+	var (
+		req = httptest.NewRequest(method, "/items", nil)
+		res = httptest.NewRecorder()
+	)
+
+	if origin != "" {
+		req.Header.Set(bottleneck.HeaderOrigin, origin)
+	}
+
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	s.router.ServeHTTP(res, req)
+	return res
+}
+
+func (s *CORSTestSuite) TestPreflight() {
+	res := s.serve(http.MethodOptions, "https://example.com", map[string]string{
+		bottleneck.HeaderAccessControlRequestMethod:  http.MethodPost,
+		bottleneck.HeaderAccessControlRequestHeaders: "Content-Type, X-Custom",
+	})
+
+	s.Equal(http.StatusNoContent, res.Code)
+	s.Equal("https://example.com", res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin))
+	s.Equal("true", res.Header().Get(bottleneck.HeaderAccessControlAllowCredentials))
+	s.Equal("GET, HEAD, PUT, PATCH, POST, DELETE", res.Header().Get(bottleneck.HeaderAccessControlAllowMethods))
+	s.Equal("Content-Type, X-Custom", res.Header().Get(bottleneck.HeaderAccessControlAllowHeaders))
+	s.Equal("3600", res.Header().Get(bottleneck.HeaderAccessControlMaxAge))
+	s.Equal([]string{
+		bottleneck.HeaderOrigin,
+		bottleneck.HeaderAccessControlRequestMethod,
+		bottleneck.HeaderAccessControlRequestHeaders,
+	}, res.Header()[bottleneck.HeaderVary])
+}
+
+func (s *CORSTestSuite) TestPreflightDisallowed() {
+	res := s.serve(http.MethodOptions, "https://evil.example", map[string]string{
+		bottleneck.HeaderAccessControlRequestMethod: http.MethodPost,
+	})
+
+	s.Equal(http.StatusNoContent, res.Code)
+	s.Equal("GET, HEAD, OPTIONS, POST", res.Header().Get(bottleneck.HeaderAllow))
+	s.Empty(res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin))
+	s.Empty(res.Header().Get(bottleneck.HeaderAccessControlAllowMethods))
+}
+
+func (s *CORSTestSuite) TestPlainOptions() {
+	res := s.serve(http.MethodOptions, "https://example.com", nil)
+
+	s.Equal(http.StatusNoContent, res.Code)
+	s.Equal("GET, HEAD, OPTIONS, POST", res.Header().Get(bottleneck.HeaderAllow))
+	s.Empty(res.Header().Get(bottleneck.HeaderAccessControlAllowMethods))
+}
+
+func (s *CORSTestSuite) TestSimpleRequest() {
+	for origin, allowed := range map[string]bool{
+		"https://example.com":     true,
+		"https://api.example.org": true,
+		"https://a.b.example.org": true,
+		"http://api.example.org":  false,
+		"https://example.org":     false,
+		"http://localhost.test":   true,
+		"https://example.net":     false,
+		"":                        false,
+	} {
+		res := s.serve(http.MethodGet, origin, nil)
+
+		s.Equal(http.StatusOK, res.Code)
+		s.Equal("items", res.Body.String())
+		s.Equal(bottleneck.HeaderOrigin, res.Header().Get(bottleneck.HeaderVary))
+
+		if allowed {
+			s.Equal(origin, res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin), origin)
+			s.Equal("X-Total-Count", res.Header().Get(bottleneck.HeaderAccessControlExposeHeaders), origin)
+		} else {
+			s.Empty(res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin), origin)
+			s.Empty(res.Header().Get(bottleneck.HeaderAccessControlExposeHeaders), origin)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	suite.Run(t, new(CORSTestSuite))
+}
+
+func TestCORSAllowAll(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(CORSContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(CORS(CORSOptions{AllowOrigins: []string{"*"}, AllowHeaders: []string{"Content-Type"}}))
+	group.GET("/", func(ctx *CORSContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	router.Mount(group)
+
+	var (
+		req = httptest.NewRequest(http.MethodOptions, "/", nil)
+		res = httptest.NewRecorder()
+	)
+
+	req.Header.Set(bottleneck.HeaderOrigin, "https://example.com")
+	req.Header.Set(bottleneck.HeaderAccessControlRequestMethod, http.MethodGet)
+	req.Header.Set(bottleneck.HeaderAccessControlRequestHeaders, "X-Custom")
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "*", res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "Content-Type", res.Header().Get(bottleneck.HeaderAccessControlAllowHeaders))
+	assert.Empty(t, res.Header().Get(bottleneck.HeaderAccessControlAllowCredentials))
+	assert.Empty(t, res.Header().Get(bottleneck.HeaderAccessControlMaxAge))
+}
+
+func TestCORSMixedGroups(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(CORSContext{})
+		public = bottleneck.NewGroup()
+		api    = bottleneck.NewGroup()
+	)
+
+	public.GET("/items", func(ctx *CORSContext) error {
+		return ctx.String(http.StatusOK, "items")
+	})
+
+	api.Use(CORS(CORSOptions{AllowOrigins: []string{"https://example.com"}}))
+	api.POST("/items", func(ctx *CORSContext) error {
+		return ctx.NoContent(http.StatusCreated)
+	}, func(ctx *CORSContext, next bottleneck.Next) error {
+		return bottleneck.NewError(http.StatusUnauthorized)
+	})
+
+	router.Mount(public)
+	router.Mount(api)
+
+	for method, allowed := range map[string]bool{
+		http.MethodPost: true,
+		http.MethodGet:  false,
+	} {
+		var (
+			req = httptest.NewRequest(http.MethodOptions, "/items", nil)
+			res = httptest.NewRecorder()
+		)
+
+		req.Header.Set(bottleneck.HeaderOrigin, "https://example.com")
+		req.Header.Set(bottleneck.HeaderAccessControlRequestMethod, method)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNoContent, res.Code, method)
+		assert.Equal(t, "GET, HEAD, OPTIONS, POST", res.Header().Get(bottleneck.HeaderAllow), method)
+
+		if allowed {
+			assert.Equal(t, "https://example.com", res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin), method)
+		} else {
+			assert.Empty(t, res.Header().Get(bottleneck.HeaderAccessControlAllowOrigin), method)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lukasdietrich/bottleneck"
+)
+
+var (
+	errCSRFToken  = errors.New("invalid csrf token")
+	errCSRFOrigin = errors.New("invalid csrf origin")
+)
+
+// CSRFOptions define how the CSRF middleware issues and checks tokens.
+type CSRFOptions struct {
+	// CookieName is the name of the signed cookie holding the token of the double-submit method. The default is
+	// "_csrf".
+	CookieName string
+	// FieldName is the form field, that contains the token. It is removed from the form after reading, so that it
+	// does not fail the binding of payloads. The default is "csrf_token".
+	FieldName string
+	// HeaderName is the request header, that contains the token. The default is "X-CSRF-Token".
+	HeaderName string
+	// SessionID switches from the double-submit cookie to a synchronizer token, that is bound to the session
+	// returned by SessionID. Requests without a session get an empty token and are rejected for unsafe methods.
+	SessionID func(*bottleneck.Context) string
+	// Key is used to derive synchronizer tokens from session ids. It is required, if SessionID is set.
+	Key []byte
+	// TrustedOrigins lists origins like "https://admin.example.com", which are allowed to send requests besides the
+	// origin of the request itself.
+	TrustedOrigins []string
+}
+
+type csrfKey struct{}
+
+// csrfState is stored on the context for CSRFToken and CSRFField.
+type csrfState struct {
+	token string
+	field string
+}
+
+// CSRF creates a middleware that protects against cross-site request forgery. Requests with unsafe methods must send
+// the token in a form field or header. Additionally the Origin header, or the Referer header if Origin is missing,
+// must match the scheme and host of the request or one of the trusted origins. Failed checks are returned as 403
+// *bottleneck.Error.
+//
+// By default a random token is stored in a signed cookie and must be submitted again (double-submit cookie). The
+// signature prevents attackers, who can set cookies for a sibling subdomain, from choosing the token, so
+// bottleneck.CookieOptions.Keys must be configured on the Router. With SessionID the token is derived from the session
+// instead (synchronizer token). The token of a request is available through CSRFToken and CSRFField.
+//
+//   router.Cookies.Keys = [][]byte{key}
+//   group.Use(middleware.CSRF(middleware.CSRFOptions{}))
+//
+//   group.GET("/settings", func(ctx *Context) error {
+//     return ctx.HTML(http.StatusOK, "settings.html", map[string]interface{}{
+//       "CSRFField": middleware.CSRFField(&ctx.Context),
+//     })
+//   })
+//
+// See https://cheatsheetseries.owasp.org/cheatsheets/Cross-Site_Request_Forgery_Prevention_Cheat_Sheet.html
+func CSRF(opts CSRFOptions) StandardMiddleware {
+	if opts.CookieName == "" {
+		opts.CookieName = "_csrf"
+	}
+
+	if opts.FieldName == "" {
+		opts.FieldName = "csrf_token"
+	}
+
+	if opts.HeaderName == "" {
+		opts.HeaderName = "X-CSRF-Token"
+	}
+
+	if opts.SessionID != nil && len(opts.Key) == 0 {
+		panic("csrf: a key is required for session bound tokens")
+	}
+
+	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
+		token, err := csrfToken(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		ctx.Set(csrfKey{}, csrfState{token: token, field: opts.FieldName})
+
+		if isSafeMethod(ctx.Request().Method) {
+			return next()
+		}
+
+		if !checkCSRFOrigin(ctx, opts.TrustedOrigins) {
+			return bottleneck.NewError(http.StatusForbidden).WithCause(errCSRFOrigin)
+		}
+
+		submitted := submittedCSRFToken(ctx.Request(), opts)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(submitted)) != 1 {
+			return bottleneck.NewError(http.StatusForbidden).WithCause(errCSRFToken)
+		}
+
+		return next()
+	}
+}
+
+// CSRFToken returns the token of the request set by the CSRF middleware or an empty string.
+func CSRFToken(ctx *bottleneck.Context) string {
+	return csrfStateOf(ctx).token
+}
+
+// CSRFField returns a hidden input with the token of the request for html forms or an empty string, if the CSRF
+// middleware is not used.
+//
+//   <form method="post">{{ .CSRFField }}</form>
+func CSRFField(ctx *bottleneck.Context) template.HTML {
+	state := csrfStateOf(ctx)
+	if state.field == "" {
+		return ""
+	}
+
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(state.field) +
+		`" value="` + template.HTMLEscapeString(state.token) + `">`)
+}
+
+func csrfStateOf(ctx *bottleneck.Context) csrfState {
+	value, _ := ctx.Get(csrfKey{})
+	state, _ := value.(csrfState)
+	return state
+}
+
+// csrfToken returns the synchronizer token of the session or the token of the signed cookie. A new cookie is issued,
+// if there is none or its signature is invalid.
+func csrfToken(ctx *bottleneck.Context, opts CSRFOptions) (string, error) {
+	if opts.SessionID != nil {
+		sessionID := opts.SessionID(ctx)
+		if sessionID == "" {
+			return "", nil
+		}
+
+		mac := hmac.New(sha256.New, opts.Key)
+		io.WriteString(mac, sessionID) // nolint:errcheck
+		return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+	}
+
+	cookie, err := ctx.SignedCookie(opts.CookieName)
+
+	switch {
+	case err == nil && cookie.Value != "":
+		return cookie.Value, nil
+	case errors.Is(err, bottleneck.ErrNoCookieKeys):
+		return "", err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	if err := ctx.SetSignedCookie(&http.Cookie{Name: opts.CookieName, Value: token, HttpOnly: true}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// submittedCSRFToken returns the token of the header or the form field. The field is removed from the parsed form,
+// because binders do not expect it.
+func submittedCSRFToken(req *http.Request, opts CSRFOptions) string {
+	if token := req.Header.Get(opts.HeaderName); token != "" {
+		return token
+	}
+
+	contentType := req.Header.Get(bottleneck.HeaderContentType)
+
+	if !strings.HasPrefix(contentType, bottleneck.MIMEApplicationForm) &&
+		!strings.HasPrefix(contentType, bottleneck.MIMEMultipartForm) {
+		return ""
+	}
+
+	token := req.PostFormValue(opts.FieldName)
+
+	req.PostForm.Del(opts.FieldName)
+	req.Form.Del(opts.FieldName)
+
+	if req.MultipartForm != nil {
+		delete(req.MultipartForm.Value, opts.FieldName)
+	}
+
+	return token
+}
+
+// checkCSRFOrigin compares the Origin or Referer header to the origin of the request and the trusted origins.
+// Requests without both headers are accepted, because some clients omit them, and are protected by the token alone.
+func checkCSRFOrigin(ctx *bottleneck.Context, trusted []string) bool {
+	req := ctx.Request()
+
+	origin := req.Header.Get(bottleneck.HeaderOrigin)
+	if origin == "" {
+		referer := req.Header.Get(bottleneck.HeaderReferer)
+		if referer == "" {
+			return true
+		}
+
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return false
+		}
+
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	if strings.EqualFold(origin, ctx.Scheme()+"://"+ctx.Host()) {
+		return true
+	}
+
+	for _, t := range trusted {
+		if strings.EqualFold(origin, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
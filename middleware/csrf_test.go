@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CSRFContext struct {
+	bottleneck.Context
+}
+
+type CSRFTestPayload struct {
+	Name string `form:"name"`
+}
+
+type CSRFTestSuite struct {
+	suite.Suite
+	router *bottleneck.Router
+}
+
+func (s *CSRFTestSuite) SetupTest() {
+	var (
+		router = bottleneck.NewRouter(CSRFContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	router.Cookies.Keys = [][]byte{[]byte("csrf-test-key")}
+
+	group.Use(CSRF(CSRFOptions{TrustedOrigins: []string{"https://admin.example.com"}}))
+	group.GET("/form", func(ctx *CSRFContext) error {
+		return ctx.String(http.StatusOK, CSRFToken(&ctx.Context))
+	})
+	group.GET("/field", func(ctx *CSRFContext) error {
+		return ctx.String(http.StatusOK, string(CSRFField(&ctx.Context)))
+	})
+	group.POST("/form", func(ctx *CSRFContext) error {
+		return ctx.String(http.StatusOK, "saved")
+	})
+	group.POST("/bind", func(ctx *CSRFContext, payload *CSRFTestPayload) error {
+		return ctx.String(http.StatusOK, "saved "+payload.Name)
+	})
+
+	router.Mount(group)
+	s.router = router
+}
+
+// token requests the form and returns the issued cookie and token.
+func (s *CSRFTestSuite) token() (*http.Cookie, string) {
+	res := httptest.NewRecorder()
+	s.router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/form", nil))
+
+	s.Equal(http.StatusOK, res.Code)
+
+	cookie := res.Result().Cookies()[0]
+	s.Equal("_csrf", cookie.Name)
+	s.True(cookie.HttpOnly)
+
+	token := res.Body.String()
+	s.NotEmpty(token)
+	s.NotEqual(token, cookie.Value)
+
+	return cookie, token
+}
+
+func (s *CSRFTestSuite) post(cookie *http.Cookie, form url.Values, header map[string]string) *httptest.ResponseRecorder {
+	return s.postPath("/form", cookie, form, header)
+}
+
+func (s *CSRFTestSuite) postPath(
+	path string, cookie *http.Cookie, form url.Values, header map[string]string,
+) *httptest.ResponseRecorder {
+	var (
+		req = httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		res = httptest.NewRecorder()
+	)
+
+	req.Header.Set(bottleneck.HeaderContentType, bottleneck.MIMEApplicationForm)
+
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	s.router.ServeHTTP(res, req)
+	return res
+}
+
+func (s *CSRFTestSuite) TestFormField() {
+	cookie, token := s.token()
+
+	res := s.post(cookie, url.Values{"csrf_token": {token}}, map[string]string{
+		bottleneck.HeaderOrigin: "http://example.com",
+	})
+
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal("saved", res.Body.String())
+}
+
+func (s *CSRFTestSuite) TestField() {
+	cookie, token := s.token()
+
+	var (
+		req = httptest.NewRequest(http.MethodGet, "/field", nil)
+		res = httptest.NewRecorder()
+	)
+
+	req.AddCookie(cookie)
+	s.router.ServeHTTP(res, req)
+
+	s.Equal(`<input type="hidden" name="csrf_token" value="`+token+`">`, res.Body.String())
+	s.Empty(res.Result().Cookies())
+}
+
+func (s *CSRFTestSuite) TestFormBinding() {
+	cookie, token := s.token()
+
+	res := s.postPath("/bind", cookie, url.Values{"csrf_token": {token}, "name": {"Jake"}}, nil)
+
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal("saved Jake", res.Body.String())
+}
+
+func (s *CSRFTestSuite) TestHeader() {
+	cookie, token := s.token()
+
+	res := s.post(cookie, nil, map[string]string{
+		"X-CSRF-Token":           token,
+		bottleneck.HeaderReferer: "https://admin.example.com/dashboard",
+	})
+
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func (s *CSRFTestSuite) TestInvalidToken() {
+	cookie, token := s.token()
+
+	for _, res := range []*httptest.ResponseRecorder{
+		s.post(cookie, nil, nil),
+		s.post(cookie, url.Values{"csrf_token": {"forged"}}, nil),
+		s.post(nil, url.Values{"csrf_token": {token}}, nil),
+		s.post(&http.Cookie{Name: "_csrf", Value: "forged"}, url.Values{"csrf_token": {"forged"}}, nil),
+	} {
+		s.Equal(http.StatusForbidden, res.Code)
+		s.JSONEq(`{"status":403,"message":"Forbidden"}`, res.Body.String())
+	}
+}
+
+func (s *CSRFTestSuite) TestInvalidOrigin() {
+	cookie, token := s.token()
+
+	for _, header := range []map[string]string{
+		{bottleneck.HeaderOrigin: "https://evil.example"},
+		{bottleneck.HeaderOrigin: "null"},
+		{bottleneck.HeaderReferer: "https://evil.example/form"},
+	} {
+		res := s.post(cookie, url.Values{"csrf_token": {token}}, header)
+		s.Equal(http.StatusForbidden, res.Code)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	suite.Run(t, new(CSRFTestSuite))
+}
+
+func TestCSRFWithoutCookieKeys(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(CSRFContext{})
+		group  = bottleneck.NewGroup()
+		res    = httptest.NewRecorder()
+	)
+
+	group.Use(CSRF(CSRFOptions{}))
+	group.GET("/", func(ctx *CSRFContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	router.Mount(group)
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+}
+
+func TestCSRFSession(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(CSRFContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(CSRF(CSRFOptions{
+		Key: []byte("secret"),
+		SessionID: func(ctx *bottleneck.Context) string {
+			return ctx.Request().Header.Get("X-Session")
+		},
+	}))
+	group.GET("/", func(ctx *CSRFContext) error {
+		return ctx.String(http.StatusOK, CSRFToken(&ctx.Context))
+	})
+	group.DELETE("/", func(ctx *CSRFContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	router.Mount(group)
+
+	serve := func(method, session, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		req.Header.Set("X-Session", session)
+		req.Header.Set("X-CSRF-Token", token)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve(http.MethodGet, "session-a", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, res.Result().Cookies())
+
+	token := res.Body.String()
+	assert.NotEmpty(t, token)
+	assert.Equal(t, token, serve(http.MethodGet, "session-a", "").Body.String())
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "session-a", token).Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodDelete, "session-b", token).Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodDelete, "", "").Code)
+
+	assert.Panics(t, func() {
+		CSRF(CSRFOptions{SessionID: func(*bottleneck.Context) string { return "" }})
+	})
+}
@@ -34,10 +34,21 @@ func (l *limitedReader) Read(b []byte) (int, error) {
 	return 0, bottleneck.NewError(http.StatusRequestEntityTooLarge).WithCause(errLimitExceeded)
 }
 
-// Limit creates a middleware that returns an error when a request body is larger than size.
+// Limit creates a middleware that returns an error when a request body is larger than size. A body, that is already
+// buffered, is checked right away, because it can be read more than once.
 func Limit(size int64) StandardMiddleware {
 	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
-		if req := ctx.Request(); req.Body != nil {
+		req := ctx.Request()
+
+		if seeker, ok := req.Body.(io.Seeker); ok {
+			if err := checkBufferedSize(seeker, size); err != nil {
+				return err
+			}
+
+			return next()
+		}
+
+		if req.Body != nil {
 			req.Body = &limitedReader{
 				reader: req.Body,
 				count:  0,
@@ -48,3 +59,20 @@ func Limit(size int64) StandardMiddleware {
 		return next()
 	}
 }
+
+func checkBufferedSize(seeker io.Seeker, size int64) error {
+	bodySize, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if bodySize > size {
+		return bottleneck.NewError(http.StatusRequestEntityTooLarge).WithCause(errLimitExceeded)
+	}
+
+	return nil
+}
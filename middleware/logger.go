@@ -12,6 +12,8 @@ import (
 // Logger creates a middleware that logs requests after the handler is called.
 //
 // If an error occurs the status is set to 500 or the status of a bottleneck.Error respectively.
+// The client address is resolved with Context.RealIP, so it respects the trusted proxies of the router.
+// The id of the request is logged as well, if it is set by RequestID before.
 // Logger should be added before Recover to log panics.
 func Logger() StandardMiddleware {
 	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
@@ -25,8 +27,13 @@ func Logger() StandardMiddleware {
 
 			bErr *bottleneck.Error
 			sErr string
+			id   string
 		)
 
+		if requestID := ctx.RequestID(); requestID != "" {
+			id = " id=" + requestID
+		}
+
 		if err != nil {
 			if errors.As(err, &bErr) {
 				if bErr.Cause != nil {
@@ -39,11 +46,13 @@ func Logger() StandardMiddleware {
 			}
 		}
 
-		log.Printf("| %3d | %12s | %6s %s\n%s",
+		log.Printf("| %3d | %12s | %15s | %6s %s%s\n%s",
 			status,
 			latency,
+			ctx.RealIP(),
 			req.Method,
 			req.URL,
+			id,
 			sErr)
 
 		return err
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/lukasdietrich/bottleneck"
+)
+
+// PrettyOptions define how the Pretty middleware behaves.
+type PrettyOptions struct {
+	// Param is the query parameter, that enables indentation. The default is "pretty".
+	Param string
+	// Indent is used to indent JSON. The default is two spaces.
+	Indent string
+}
+
+// Pretty creates a middleware that indents JSON responses, when the query parameter is present and not "false" or
+// "0". The query parameter is removed from the request, so that it does not reach the Binder.
+//
+//   // curl "localhost:8080/users?pretty"
+//   group.Use(middleware.Pretty(middleware.PrettyOptions{}))
+func Pretty(opts PrettyOptions) StandardMiddleware {
+	if opts.Param == "" {
+		opts.Param = "pretty"
+	}
+
+	if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+
+	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
+		var (
+			req   = ctx.Request()
+			query = req.URL.Query()
+		)
+
+		if values, ok := query[opts.Param]; ok {
+			query.Del(opts.Param)
+			req.URL.RawQuery = query.Encode()
+
+			if !isFalse(values) {
+				jsonOpts := ctx.JSONOptions()
+				jsonOpts.Indent = opts.Indent
+				ctx.SetJSONOptions(jsonOpts)
+			}
+		}
+
+		return next()
+	}
+}
+
+func isFalse(values []string) bool {
+	return len(values) > 0 && (values[0] == "false" || values[0] == "0")
+}
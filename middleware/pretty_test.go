@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+)
+
+type PrettyContext struct {
+	bottleneck.Context
+}
+
+func TestPretty(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(PrettyContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(Pretty(PrettyOptions{}))
+	group.GET("/", func(ctx *bottleneck.Context) error {
+		return ctx.JSON(http.StatusOK, map[string]int{"a": 1})
+	})
+
+	router.Mount(group)
+
+	for target, expected := range map[string]string{
+		"/":             `{"a":1}`,
+		"/?pretty":      "{\n  \"a\": 1\n}",
+		"/?pretty=true": "{\n  \"a\": 1\n}",
+		"/?pretty=0":    `{"a":1}`,
+	} {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, expected, res.Body.String(), target)
+	}
+}
+
+type PrettyTestQuery struct {
+	Name string `query:"name"`
+}
+
+func TestPrettyBind(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(PrettyContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(Pretty(PrettyOptions{}))
+	group.GET("/", func(ctx *bottleneck.Context, query *PrettyTestQuery) error {
+		return ctx.JSON(http.StatusOK, query)
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/?pretty&name=joe", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "{\n  \"Name\": \"joe\"\n}", res.Body.String())
+}
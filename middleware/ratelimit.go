@@ -0,0 +1,413 @@
+package middleware
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lukasdietrich/bottleneck"
+)
+
+var (
+	errRateLimitExceeded = errors.New("rate limit exceeded")
+
+	baseContextPtrType = reflect.TypeOf((*bottleneck.Context)(nil))
+	nextType           = reflect.TypeOf((bottleneck.Next)(nil))
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// RateLimitState is the state of a single key. Its meaning depends on the RateLimitAlgorithm, so stores should treat
+// it as opaque.
+type RateLimitState struct {
+	// Count is the number of tokens of a bucket or the number of requests in the current window.
+	Count float64
+	// Previous is the number of requests in the previous window.
+	Previous float64
+	// Time is the last refill of a bucket or the start of the current window.
+	Time time.Time
+}
+
+// RateLimitResult describes a limit after a request was counted.
+type RateLimitResult struct {
+	// Allowed reports whether the request is within the limit.
+	Allowed bool
+	// Limit is the number of requests allowed at once.
+	Limit int
+	// Remaining is the number of requests left.
+	Remaining int
+	// Reset is the duration until the limit is fully restored.
+	Reset time.Duration
+	// RetryAfter is the duration until the next request is allowed, if the request is not allowed.
+	RetryAfter time.Duration
+}
+
+// A RateLimitAlgorithm counts a request against the state of a key.
+type RateLimitAlgorithm interface {
+	// Take counts a request at the given time and updates the state.
+	Take(state *RateLimitState, now time.Time) RateLimitResult
+	// TTL is the duration after which an unused state equals a new state and can be discarded.
+	TTL() time.Duration
+}
+
+// TokenBucket allows bursts of up to Burst requests, while tokens are refilled at a rate of Limit per Period.
+type TokenBucket struct {
+	Limit  int
+	Period time.Duration
+	// Burst is the capacity of the bucket. The default is Limit.
+	Burst int
+}
+
+func (b TokenBucket) validate() error {
+	if b.Limit <= 0 || b.Period <= 0 || b.Burst < 0 {
+		return fmt.Errorf("ratelimit: invalid token bucket %+v", b)
+	}
+
+	return nil
+}
+
+func (b TokenBucket) capacity() float64 {
+	if b.Burst > 0 {
+		return float64(b.Burst)
+	}
+
+	return float64(b.Limit)
+}
+
+// rate returns the number of tokens refilled per nanosecond.
+func (b TokenBucket) rate() float64 {
+	return float64(b.Limit) / float64(b.Period)
+}
+
+// Take takes a token from the bucket.
+func (b TokenBucket) Take(state *RateLimitState, now time.Time) RateLimitResult {
+	var (
+		capacity = b.capacity()
+		rate     = b.rate()
+	)
+
+	if state.Time.IsZero() {
+		state.Count = capacity
+	} else {
+		state.Count = math.Min(capacity, state.Count+float64(now.Sub(state.Time))*rate)
+	}
+
+	state.Time = now
+
+	result := RateLimitResult{Limit: int(capacity)}
+
+	if state.Count >= 1 {
+		state.Count--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - state.Count) / rate))
+	}
+
+	result.Remaining = int(state.Count)
+	result.Reset = time.Duration(math.Ceil((capacity - state.Count) / rate))
+
+	return result
+}
+
+// TTL is the duration to refill an empty bucket.
+func (b TokenBucket) TTL() time.Duration {
+	return time.Duration(math.Ceil(b.capacity() / b.rate()))
+}
+
+// SlidingWindow allows Limit requests per Window. The requests of the previous window are weighted by its overlap
+// with a window ending now, which smooths the bursts at the boundaries of fixed windows.
+type SlidingWindow struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (w SlidingWindow) validate() error {
+	if w.Limit <= 0 || w.Window <= 0 {
+		return fmt.Errorf("ratelimit: invalid sliding window %+v", w)
+	}
+
+	return nil
+}
+
+// Take counts a request in the current window.
+func (w SlidingWindow) Take(state *RateLimitState, now time.Time) RateLimitResult {
+	switch elapsed := now.Sub(state.Time); {
+	case state.Time.IsZero() || elapsed >= 2*w.Window:
+		state.Count, state.Previous, state.Time = 0, 0, now.Truncate(w.Window)
+	case elapsed >= w.Window:
+		state.Count, state.Previous, state.Time = 0, state.Count, state.Time.Add(w.Window)
+	}
+
+	var (
+		limit     = float64(w.Limit)
+		progress  = float64(now.Sub(state.Time)) / float64(w.Window)
+		estimated = state.Previous*(1-progress) + state.Count
+		result    = RateLimitResult{Limit: w.Limit}
+		windowEnd = state.Time.Add(w.Window).Sub(now)
+	)
+
+	if estimated < limit {
+		state.Count++
+		estimated++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = w.retryAfter(state, progress, windowEnd)
+	}
+
+	result.Remaining = int(math.Max(0, limit-estimated))
+	result.Reset = windowEnd
+	if state.Count > 0 {
+		result.Reset += w.Window
+	}
+
+	return result
+}
+
+// retryAfter calculates when the weighted count drops below the limit again. The extra nanosecond accounts for the
+// weighted count having to be strictly less than the limit.
+func (w SlidingWindow) retryAfter(state *RateLimitState, progress float64, windowEnd time.Duration) time.Duration {
+	limit := float64(w.Limit)
+
+	if state.Count < limit {
+		// The previous window still weighs too much: previous * (1 - p) + count < limit
+		target := 1 - (limit-state.Count)/state.Previous
+		return time.Duration((target-progress)*float64(w.Window)) + 1
+	}
+
+	// The current window is full and becomes the previous window: count * (1 - p) < limit
+	target := 1 - limit/state.Count
+	return windowEnd + time.Duration(target*float64(w.Window)) + 1
+}
+
+// TTL is the duration until a window and its successor have passed.
+func (w SlidingWindow) TTL() time.Duration {
+	return 2 * w.Window
+}
+
+// A RateLimitStore holds the states of rate limits. The default store keeps them in memory, but external backends
+// can be used to share limits between several instances.
+type RateLimitStore interface {
+	// Take counts a request of key using the algorithm. It must be safe for concurrent use.
+	Take(key string, algorithm RateLimitAlgorithm, now time.Time) (RateLimitResult, error)
+}
+
+// DefaultMemoryRateLimitStoreSize is the default MaxEntries of a MemoryRateLimitStore.
+const DefaultMemoryRateLimitStoreSize = 100000
+
+// MemoryRateLimitStore is a RateLimitStore, that keeps the states in memory. Unused states are discarded after the
+// TTL of their algorithm.
+type MemoryRateLimitStore struct {
+	// MaxEntries is the maximum number of states. When it is reached, the least recently used state is discarded,
+	// which resets the limit of its key. Zero means no limit. The default is DefaultMemoryRateLimitStoreSize.
+	MaxEntries int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	recent  *list.List
+	sweep   time.Time
+}
+
+type memoryRateLimitEntry struct {
+	key     string
+	state   RateLimitState
+	expires time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		MaxEntries: DefaultMemoryRateLimitStoreSize,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+// Take counts a request of key using the algorithm.
+func (s *MemoryRateLimitStore) Take(key string, algorithm RateLimitAlgorithm, now time.Time) (RateLimitResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.After(s.sweep) {
+		for _, element := range s.entries {
+			if now.After(element.Value.(*memoryRateLimitEntry).expires) {
+				s.remove(element)
+			}
+		}
+
+		s.sweep = now.Add(time.Minute)
+	}
+
+	element, ok := s.entries[key]
+	if ok && now.After(element.Value.(*memoryRateLimitEntry).expires) {
+		s.remove(element)
+		ok = false
+	}
+
+	if ok {
+		s.recent.MoveToFront(element)
+	} else {
+		for s.MaxEntries > 0 && s.recent.Len() >= s.MaxEntries {
+			s.remove(s.recent.Back())
+		}
+
+		element = s.recent.PushFront(&memoryRateLimitEntry{key: key})
+		s.entries[key] = element
+	}
+
+	entry := element.Value.(*memoryRateLimitEntry)
+	result := algorithm.Take(&entry.state, now)
+	entry.expires = now.Add(algorithm.TTL())
+
+	return result, nil
+}
+
+func (s *MemoryRateLimitStore) remove(element *list.Element) {
+	delete(s.entries, element.Value.(*memoryRateLimitEntry).key)
+	s.recent.Remove(element)
+}
+
+// RateLimitOptions define how the RateLimit middleware limits requests.
+type RateLimitOptions struct {
+	// Algorithm is either a TokenBucket, a SlidingWindow or a custom RateLimitAlgorithm.
+	Algorithm RateLimitAlgorithm
+	// Key groups requests, that share a limit. Requests with an empty key are not limited. The default is KeyByIP.
+	// Key is a func(*bottleneck.Context) string or a func(*CustomContext) string, so that limits per user can read
+	// the user from the custom context of an authentication middleware.
+	Key interface{}
+	// Store holds the states of the limit. The default is a new MemoryRateLimitStore, so every use of RateLimit has
+	// its own limits. Stores, that are shared between limits, need distinct keys.
+	Store RateLimitStore
+}
+
+// RateLimit creates a middleware that limits the number of requests per key. Every response contains the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers. Requests exceeding the limit are rejected with a
+// 429 *bottleneck.Error and a Retry-After header.
+//
+// RateLimit can be used for groups and single routes alike:
+//
+//   group.Use(middleware.RateLimit(middleware.RateLimitOptions{
+//     Algorithm: middleware.SlidingWindow{Limit: 100, Window: time.Minute},
+//   }))
+//
+//   group.POST("/login", login, middleware.RateLimit(middleware.RateLimitOptions{
+//     Algorithm: middleware.TokenBucket{Limit: 5, Period: time.Minute, Burst: 10},
+//   }))
+//
+// The returned bottleneck.Middleware takes the context type of the Key. RateLimit panics, if the algorithm is
+// missing or invalid, or if the Key is not a valid func.
+//
+// See https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+func RateLimit(opts RateLimitOptions) bottleneck.Middleware {
+	if opts.Algorithm == nil {
+		panic("ratelimit: an algorithm is required")
+	}
+
+	if algorithm, ok := opts.Algorithm.(interface{ validate() error }); ok {
+		if err := algorithm.validate(); err != nil {
+			panic(err)
+		}
+	}
+
+	if opts.Key == nil {
+		opts.Key = KeyByIP
+	}
+
+	if opts.Store == nil {
+		opts.Store = NewMemoryRateLimitStore()
+	}
+
+	if key, ok := opts.Key.(func(*bottleneck.Context) string); ok {
+		return StandardMiddleware(func(ctx *bottleneck.Context, next bottleneck.Next) error {
+			return limitRate(ctx, key(ctx), opts, next)
+		})
+	}
+
+	return reflectRateLimit(opts)
+}
+
+// reflectRateLimit creates a middleware with the custom context of the Key as its first argument.
+func reflectRateLimit(opts RateLimitOptions) bottleneck.Middleware {
+	var (
+		keyValue = reflect.ValueOf(opts.Key)
+		keyType  = keyValue.Type()
+	)
+
+	if keyType.Kind() != reflect.Func || keyType.NumIn() != 1 || keyType.NumOut() != 1 ||
+		keyType.Out(0).Kind() != reflect.String ||
+		keyType.In(0).Kind() != reflect.Ptr || keyType.In(0).Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("ratelimit: key must be a func(*CustomContext) string, got %s", keyType))
+	}
+
+	embedded, ok := keyType.In(0).Elem().FieldByName("Context")
+	if !ok || !embedded.Anonymous || reflect.PtrTo(embedded.Type) != baseContextPtrType {
+		panic(fmt.Errorf("ratelimit: %s does not embed bottleneck.Context", keyType.In(0)))
+	}
+
+	middlewareType := reflect.FuncOf([]reflect.Type{keyType.In(0), nextType}, []reflect.Type{errorType}, false)
+
+	return reflect.MakeFunc(middlewareType, func(args []reflect.Value) []reflect.Value {
+		var (
+			ctx  = args[0].Elem().FieldByIndex(embedded.Index).Addr().Interface().(*bottleneck.Context)
+			key  = keyValue.Call(args[:1])[0].String()
+			next = args[1].Interface().(bottleneck.Next)
+			err  = limitRate(ctx, key, opts, next)
+		)
+
+		return []reflect.Value{reflect.ValueOf(&err).Elem()}
+	}).Interface()
+}
+
+// limitRate counts the request against the limit of the key.
+func limitRate(ctx *bottleneck.Context, key string, opts RateLimitOptions, next bottleneck.Next) error {
+	if key == "" {
+		return next()
+	}
+
+	result, err := opts.Store.Take(key, opts.Algorithm, time.Now())
+	if err != nil {
+		return err
+	}
+
+	header := ctx.Response().Header()
+	header.Set(bottleneck.HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+	header.Set(bottleneck.HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	header.Set(bottleneck.HeaderRateLimitReset, formatSeconds(result.Reset))
+
+	if !result.Allowed {
+		header.Set(bottleneck.HeaderRetryAfter, formatSeconds(result.RetryAfter))
+		return bottleneck.NewError(http.StatusTooManyRequests).WithCause(errRateLimitExceeded)
+	}
+
+	return next()
+}
+
+// KeyByIP uses the ip address of the client resolved with bottleneck.Context.RealIP, so clients behind trusted
+// proxies get their own limits.
+func KeyByIP(ctx *bottleneck.Context) string {
+	return ctx.RealIP()
+}
+
+// KeyByHeader uses the value of a request header, e.g. an api key. Requests without the header fall back to KeyByIP.
+// The header must be validated by an earlier middleware like an authentication, because clients get a new limit for
+// every value they send otherwise.
+//
+//   middleware.RateLimitOptions{Key: middleware.KeyByHeader("X-API-Key")}
+func KeyByHeader(name string) func(*bottleneck.Context) string {
+	return func(ctx *bottleneck.Context) string {
+		if value := ctx.Request().Header.Get(name); value != "" {
+			return "header:" + value
+		}
+
+		return "ip:" + KeyByIP(ctx)
+	}
+}
+
+// formatSeconds formats a duration as seconds rounded up.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+)
+
+type RateLimitContext struct {
+	bottleneck.Context
+	User string
+}
+
+func TestTokenBucket(t *testing.T) {
+	var (
+		bucket = TokenBucket{Limit: 1, Period: time.Second, Burst: 3}
+		state  RateLimitState
+		now    = time.Date(2020, time.May, 17, 12, 0, 0, 0, time.UTC)
+	)
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result := bucket.Take(&state, now)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result := bucket.Take(&state, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	result = bucket.Take(&state, now.Add(time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result = bucket.Take(&state, now.Add(time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+
+	assert.Equal(t, 3*time.Second, bucket.TTL())
+}
+
+func TestSlidingWindow(t *testing.T) {
+	var (
+		window = SlidingWindow{Limit: 4, Window: time.Minute}
+		state  RateLimitState
+		start  = time.Date(2020, time.May, 17, 12, 0, 0, 0, time.UTC)
+	)
+
+	for remaining := 3; remaining >= 0; remaining-- {
+		result := window.Take(&state, start)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result := window.Take(&state, start.Add(15*time.Second))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 45*time.Second+1, result.RetryAfter)
+	assert.Equal(t, 105*time.Second, result.Reset)
+
+	// Half of the previous window still counts: 4 * 0.5 = 2
+	result = window.Take(&state, start.Add(90*time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	result = window.Take(&state, start.Add(90*time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// 4 * 0.5 + 2 = 4 until the previous window weighs less than 0.5
+	result = window.Take(&state, start.Add(90*time.Second))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Duration(1), result.RetryAfter)
+
+	result = window.Take(&state, start.Add(5*time.Minute))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 3, result.Remaining)
+
+	assert.Equal(t, 2*time.Minute, window.TTL())
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	var (
+		store     = NewMemoryRateLimitStore()
+		algorithm = SlidingWindow{Limit: 1, Window: time.Second}
+		now       = time.Date(2020, time.May, 17, 12, 0, 0, 0, time.UTC)
+	)
+
+	result, err := store.Take("a", algorithm, now)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, _ = store.Take("a", algorithm, now)
+	assert.False(t, result.Allowed)
+
+	result, _ = store.Take("b", algorithm, now)
+	assert.True(t, result.Allowed)
+	assert.Len(t, store.entries, 2)
+
+	result, _ = store.Take("a", algorithm, now.Add(time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Len(t, store.entries, 1)
+}
+
+func TestMemoryRateLimitStoreMaxEntries(t *testing.T) {
+	var (
+		store     = NewMemoryRateLimitStore()
+		algorithm = SlidingWindow{Limit: 1, Window: time.Second}
+		now       = time.Date(2020, time.May, 17, 12, 0, 0, 0, time.UTC)
+	)
+
+	store.MaxEntries = 2
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		_, err := store.Take(key, algorithm, now)
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, store.entries, 2)
+	assert.Equal(t, 2, store.recent.Len())
+
+	// "b" was the least recently used key and has been discarded.
+	result, _ := store.Take("b", algorithm, now)
+	assert.True(t, result.Allowed)
+
+	result, _ = store.Take("c", algorithm, now)
+	assert.False(t, result.Allowed)
+}
+
+func TestRateLimit(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(RateLimitContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	assert.NoError(t, router.TrustProxies("10.0.0.0/8"))
+
+	group.Use(RateLimit(RateLimitOptions{
+		Algorithm: SlidingWindow{Limit: 2, Window: time.Hour},
+	}))
+	group.GET("/", func(ctx *RateLimitContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+	group.GET("/strict", func(ctx *RateLimitContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	}, RateLimit(RateLimitOptions{
+		Algorithm: TokenBucket{Limit: 1, Period: time.Hour},
+		Key:       KeyByHeader("X-API-Key"),
+	}))
+
+	router.Mount(group)
+
+	serve := func(path, remoteAddr, forwardedFor, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+
+		if forwardedFor != "" {
+			req.Header.Set(bottleneck.HeaderXForwardedFor, forwardedFor)
+		}
+
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve("/", "10.0.0.1:4711", "203.0.113.1", "")
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "2", res.Header().Get(bottleneck.HeaderRateLimitLimit))
+	assert.Equal(t, "1", res.Header().Get(bottleneck.HeaderRateLimitRemaining))
+	assert.NotEmpty(t, res.Header().Get(bottleneck.HeaderRateLimitReset))
+
+	assert.Equal(t, http.StatusNoContent, serve("/", "10.0.0.2:4711", "203.0.113.1", "").Code)
+
+	res = serve("/", "10.0.0.1:4711", "203.0.113.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "0", res.Header().Get(bottleneck.HeaderRateLimitRemaining))
+	assert.NotEmpty(t, res.Header().Get(bottleneck.HeaderRetryAfter))
+	assert.JSONEq(t, `{"status":429,"message":"Too Many Requests"}`, res.Body.String())
+
+	// Another client behind the same proxy has its own limit.
+	assert.Equal(t, http.StatusNoContent, serve("/", "10.0.0.1:4711", "203.0.113.2", "").Code)
+
+	// Route limits apply in addition to group limits.
+	assert.Equal(t, http.StatusNoContent, serve("/strict", "192.0.2.1:4711", "", "key-a").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("/strict", "192.0.2.2:4711", "", "key-a").Code)
+	assert.Equal(t, http.StatusNoContent, serve("/strict", "192.0.2.3:4711", "", "key-b").Code)
+	// Requests without the header are limited by their ip address.
+	assert.Equal(t, http.StatusNoContent, serve("/strict", "192.0.2.4:4711", "", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("/strict", "192.0.2.4:4711", "", "").Code)
+
+	for _, opts := range []RateLimitOptions{
+		{},
+		{Algorithm: TokenBucket{Period: time.Second}},
+		{Algorithm: TokenBucket{Limit: 1}},
+		{Algorithm: SlidingWindow{Window: time.Second}},
+		{Algorithm: SlidingWindow{Limit: 1}},
+		{Algorithm: SlidingWindow{Limit: 1, Window: time.Second}, Key: "user"},
+		{Algorithm: SlidingWindow{Limit: 1, Window: time.Second}, Key: func(*struct{}) string { return "" }},
+	} {
+		assert.Panics(t, func() { RateLimit(opts) }, "%+v", opts)
+	}
+}
+
+func TestRateLimitCustomContextKey(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(RateLimitContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(func(ctx *RateLimitContext, next bottleneck.Next) error {
+		ctx.User = ctx.Request().Header.Get("X-User")
+		return next()
+	})
+	group.Use(RateLimit(RateLimitOptions{
+		Algorithm: SlidingWindow{Limit: 1, Window: time.Hour},
+		Key: func(ctx *RateLimitContext) string {
+			return ctx.User
+		},
+	}))
+	group.GET("/", func(ctx *RateLimitContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	router.Mount(group)
+
+	serve := func(user string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-User", user)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve("joe"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("joe"))
+	assert.Equal(t, http.StatusNoContent, serve("jake"))
+	assert.Equal(t, http.StatusNoContent, serve(""))
+	assert.Equal(t, http.StatusNoContent, serve(""))
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukasdietrich/bottleneck"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDOptions define how the RequestID middleware behaves.
+type RequestIDOptions struct {
+	// Header is read from the request and written to the response. The default is "X-Request-ID".
+	Header string
+	// Generate creates a new id, if the request has none or an invalid one. The default is UUIDv4.
+	Generate func() string
+	// Validate reports whether an incoming id is accepted. The default accepts up to 128 letters, digits and the
+	// characters ".", "_", ":" and "-", which includes uuids and ulids.
+	Validate func(string) bool
+}
+
+// RequestID creates a middleware that assigns an id to every request. An incoming id is reused, so that requests can
+// be traced across services. The id is stored with Context.SetRequestID, echoed in the response header and included
+// in error responses and by the Logger.
+//
+//   group.Use(middleware.RequestID(middleware.RequestIDOptions{Generate: middleware.ULID}))
+func RequestID(opts RequestIDOptions) StandardMiddleware {
+	if opts.Header == "" {
+		opts.Header = bottleneck.HeaderXRequestID
+	}
+
+	if opts.Generate == nil {
+		opts.Generate = UUIDv4
+	}
+
+	if opts.Validate == nil {
+		opts.Validate = requestIDPattern.MatchString
+	}
+
+	return func(ctx *bottleneck.Context, next bottleneck.Next) error {
+		id := ctx.Request().Header.Get(opts.Header)
+		if !opts.Validate(id) {
+			id = opts.Generate()
+		}
+
+		ctx.SetRequestID(id)
+		ctx.Response().Header().Set(opts.Header, id)
+
+		return next()
+	}
+}
+
+// UUIDv4 generates a random uuid, e.g. "5f1c6b0e-8d5a-4a3e-9c55-1f0b8e7e2d4c".
+func UUIDv4() string {
+	return uuid.New().String()
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates a lexicographically sortable id from the current time and 80 random bits, e.g.
+// "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+//
+// See https://github.com/ulid/spec
+func ULID() string {
+	var id [16]byte
+
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixNano()/int64(time.Millisecond))<<16)
+
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic(err)
+	}
+
+	// Encode 128 bits as 26 characters of 5 bits each, where the first character only holds 3 bits.
+	var (
+		hi   = binary.BigEndian.Uint64(id[:8])
+		lo   = binary.BigEndian.Uint64(id[8:])
+		text [26]byte
+	)
+
+	for i := len(text) - 1; i >= 0; i-- {
+		text[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(text[:])
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukasdietrich/bottleneck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestIDContext struct {
+	bottleneck.Context
+}
+
+type RequestIDTestSuite struct {
+	suite.Suite
+	router *bottleneck.Router
+}
+
+func (s *RequestIDTestSuite) SetupTest() {
+	var (
+		router = bottleneck.NewRouter(RequestIDContext{})
+		group  = bottleneck.NewGroup()
+	)
+
+	group.Use(RequestID(RequestIDOptions{}))
+	group.GET("/", func(ctx *RequestIDContext) error {
+		return ctx.String(http.StatusOK, ctx.RequestID())
+	})
+	group.GET("/err", func(*RequestIDContext) error {
+		return bottleneck.NewError(http.StatusInternalServerError)
+	})
+
+	router.Mount(group)
+	s.router = router
+}
+
+func (s *RequestIDTestSuite) serve(path, id string) *httptest.ResponseRecorder {
+	var (
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		res = httptest.NewRecorder()
+	)
+
+	if id != "" {
+		req.Header.Set(bottleneck.HeaderXRequestID, id)
+	}
+
+	s.router.ServeHTTP(res, req)
+	return res
+}
+
+func (s *RequestIDTestSuite) TestIncoming() {
+	res := s.serve("/", "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+
+	s.Equal("01ARZ3NDEKTSV4RRFFQ69G5FAV", res.Body.String())
+	s.Equal("01ARZ3NDEKTSV4RRFFQ69G5FAV", res.Header().Get(bottleneck.HeaderXRequestID))
+}
+
+func (s *RequestIDTestSuite) TestGenerated() {
+	for _, id := range []string{"", "<script>", string(bytes.Repeat([]byte("a"), 129))} {
+		res := s.serve("/", id)
+
+		s.Regexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, res.Body.String())
+		s.Equal(res.Body.String(), res.Header().Get(bottleneck.HeaderXRequestID))
+	}
+}
+
+func (s *RequestIDTestSuite) TestErrorResponse() {
+	res := s.serve("/err", "support-4711")
+
+	s.Equal(http.StatusInternalServerError, res.Code)
+	s.JSONEq(`{"status":500,"message":"Internal Server Error","requestId":"support-4711"}`, res.Body.String())
+}
+
+func TestRequestID(t *testing.T) {
+	suite.Run(t, new(RequestIDTestSuite))
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	var (
+		router = bottleneck.NewRouter(RequestIDContext{})
+		group  = bottleneck.NewGroup()
+		buf    bytes.Buffer
+	)
+
+	log.SetOutput(&buf)
+
+	group.Use(RequestID(RequestIDOptions{Header: "X-Trace-ID", Generate: ULID}))
+	group.Use(Logger())
+	group.GET("/", func(ctx *RequestIDContext) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	id := res.Header().Get("X-Trace-ID")
+	assert.Regexp(t, `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`, id)
+	assert.Contains(t, buf.String(), " id="+id)
+}
+
+func TestULID(t *testing.T) {
+	var previous string
+
+	for i := 0; i < 10; i++ {
+		id := ULID()
+
+		assert.Len(t, id, 26)
+		assert.NotEqual(t, previous, id)
+		if previous != "" {
+			assert.True(t, id[:10] >= previous[:10])
+		}
+
+		previous = id
+	}
+}
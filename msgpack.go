@@ -0,0 +1,40 @@
+package bottleneck
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// marshalMessagePack encodes a value as MessagePack. Structs are encoded as maps keyed by their "msgpack" tags,
+// falling back to their "json" tags.
+//
+// See https://github.com/msgpack/msgpack/blob/master/spec.md
+func marshalMessagePack(value interface{}) ([]byte, error) {
+	var (
+		buf     bytes.Buffer
+		encoder = msgpack.NewEncoder(&buf)
+	)
+
+	encoder.SetCustomStructTag("json")
+
+	if value == nil {
+		if err := encoder.EncodeNil(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	// The value is copied to make it addressable, so that methods with pointer receivers like MarshalText are used
+	// for the value and its fields.
+	addressable := reflect.New(reflect.TypeOf(value)).Elem()
+	addressable.Set(reflect.ValueOf(value))
+
+	if err := encoder.EncodeValue(addressable); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
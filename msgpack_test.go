@@ -0,0 +1,77 @@
+package bottleneck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalMessagePack(t *testing.T) {
+	type nested struct {
+		Value  float64 `msgpack:"v"`
+		Hidden string  `msgpack:"-"`
+		Empty  string  `json:"empty,omitempty"`
+	}
+
+	for _, tc := range []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{value: nil, expected: []byte{0xc0}},
+		{value: true, expected: []byte{0xc3}},
+		{value: 5, expected: []byte{0x05}},
+		{value: -5, expected: []byte{0xfb}},
+		{value: -200, expected: []byte{0xd1, 0xff, 0x38}},
+		{value: 300, expected: []byte{0xcd, 0x01, 0x2c}},
+		{value: uint64(1) << 40, expected: []byte{0xcf, 0, 0, 1, 0, 0, 0, 0, 0}},
+		{value: 1.5, expected: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{value: "hi", expected: []byte{0xa2, 'h', 'i'}},
+		{value: []byte{1, 2}, expected: []byte{0xc4, 0x02, 1, 2}},
+		{value: []string{"a"}, expected: []byte{0x91, 0xa1, 'a'}},
+		{value: map[string]int{"a": 1}, expected: []byte{0x81, 0xa1, 'a', 0x01}},
+		{value: &nested{Value: 0.5, Hidden: "x"}, expected: []byte{0x81, 0xa1, 'v', 0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0}},
+		{
+			value:    time.Unix(1, 2),
+			expected: []byte{0xd7, 0xff, 0, 0, 0, 8, 0, 0, 0, 1},
+		},
+	} {
+		b, err := marshalMessagePack(tc.value)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, b, "%#v", tc.value)
+	}
+
+	_, err := marshalMessagePack(make(chan int))
+	assert.Error(t, err)
+}
+
+type msgpackTestText struct {
+	value string
+}
+
+func (t *msgpackTestText) MarshalText() ([]byte, error) {
+	return []byte("text:" + t.value), nil
+}
+
+type msgpackTestBase struct {
+	ID int `json:"id"`
+}
+
+func TestMarshalMessagePackStruct(t *testing.T) {
+	type user struct {
+		msgpackTestBase
+		Name msgpackTestText `json:"name"`
+	}
+
+	b, err := marshalMessagePack(user{msgpackTestBase: msgpackTestBase{ID: 1}, Name: msgpackTestText{"Jake"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x82,
+		0xa2, 'i', 'd', 0x01,
+		0xa4, 'n', 'a', 'm', 'e', 0xc4, 0x09, 't', 'e', 'x', 't', ':', 'J', 'a', 'k', 'e',
+	}, b)
+
+	b, err = marshalMessagePack(msgpackTestText{"Joe"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xc4, 0x08, 't', 'e', 'x', 't', ':', 'J', 'o', 'e'}, b)
+}
@@ -0,0 +1,258 @@
+package bottleneck
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultNegotiation is used by contexts, that are not created by a Router.
+var defaultNegotiation = NewNegotiation()
+
+// A RendererFactory creates a Renderer for a response value.
+type RendererFactory func(value interface{}) Renderer
+
+type negotiationEntry struct {
+	mediaType string
+	factory   RendererFactory
+}
+
+// Negotiation chooses a Renderer based on the Accept header of a request. Media types are tested in the order they
+// are registered.
+//
+//   router.Negotiation.Register("application/x-yaml", func(value interface{}) bottleneck.Renderer {
+//     return YAMLRenderer{Value: value}
+//   })
+type Negotiation struct {
+	entries []negotiationEntry
+
+	// Default is the media type, that is used when a request has no valid Accept header or when it accepts several
+	// registered media types equally.
+	Default string
+}
+
+// NewNegotiation creates a Negotiation with renderers for JSON, XML, YAML and MessagePack. JSON is the default.
+//
+// CSV and NDJSON are not registered, because they cannot render arbitrary values. They can be registered for routes,
+// that only respond with matching values.
+func NewNegotiation() *Negotiation {
+	n := &Negotiation{Default: MIMEApplicationJSON}
+
+	n.Register(MIMEApplicationJSON, func(value interface{}) Renderer { return JSONRenderer{Value: value} })
+	n.Register(MIMEApplicationXML, func(value interface{}) Renderer { return XMLRenderer{Value: value} })
+	n.Register(MIMETextXML, func(value interface{}) Renderer { return XMLRenderer{Value: value} })
+	n.Register(MIMEApplicationYAML, func(value interface{}) Renderer { return YAMLRenderer{Value: value} })
+	n.Register("application/x-yaml", func(value interface{}) Renderer { return YAMLRenderer{Value: value} })
+	n.Register(MIMEApplicationMessagePack, func(value interface{}) Renderer { return MessagePackRenderer{Value: value} })
+
+	return n
+}
+
+// Register adds a RendererFactory for a media type. A factory registered earlier for the same media type is replaced.
+func (n *Negotiation) Register(mediaType string, factory RendererFactory) *Negotiation {
+	mediaType = strings.ToLower(mediaType)
+
+	for i, entry := range n.entries {
+		if entry.mediaType == mediaType {
+			n.entries[i].factory = factory
+			return n
+		}
+	}
+
+	n.entries = append(n.entries, negotiationEntry{mediaType: mediaType, factory: factory})
+	return n
+}
+
+// Renderer returns a Renderer for the value, that is acceptable for the request. If no registered media type is
+// acceptable, a 406 *Error is returned.
+func (n *Negotiation) Renderer(req *http.Request, value interface{}) (Renderer, error) {
+	entry, ok := n.choose(req.Header.Get(HeaderAccept))
+	if !ok {
+		return nil, NewError(http.StatusNotAcceptable)
+	}
+
+	return entry.factory(value), nil
+}
+
+// errorRenderer returns a Renderer for an *Error, that is acceptable for the request. Renderers, which cannot render
+// errors like CSVRenderer and NDJSONRenderer, are replaced by the Default, just like unacceptable requests, so that
+// errors are always displayed.
+func (n *Negotiation) errorRenderer(req *http.Request, err *Error) Renderer {
+	r, nerr := n.Renderer(req, err)
+	if nerr != nil {
+		return n.defaultRenderer(err)
+	}
+
+	switch r.(type) {
+	case CSVRenderer, NDJSONRenderer:
+		return n.defaultRenderer(err)
+	default:
+		return r
+	}
+}
+
+// defaultRenderer returns a Renderer for the Default media type regardless of the request.
+func (n *Negotiation) defaultRenderer(value interface{}) Renderer {
+	if entry, ok := n.entry(n.Default); ok {
+		return entry.factory(value)
+	}
+
+	return JSONRenderer{Value: value}
+}
+
+func (n *Negotiation) entry(mediaType string) (negotiationEntry, bool) {
+	mediaType = strings.ToLower(mediaType)
+
+	for _, entry := range n.entries {
+		if entry.mediaType == mediaType {
+			return entry, true
+		}
+	}
+
+	return negotiationEntry{}, false
+}
+
+// choose selects the registered media type with the highest quality. Ties are broken by the specificity of the
+// matching range, then by the Default and finally by the order of registration. Vendor media types with a "+json" or
+// "+xml" suffix match their base media type.
+func (n *Negotiation) choose(accept string) (negotiationEntry, bool) {
+	ranges := parseAccept(accept)
+
+	if len(ranges) == 0 {
+		if entry, ok := n.entry(n.Default); ok {
+			return entry, true
+		}
+
+		if len(n.entries) > 0 {
+			return n.entries[0], true
+		}
+
+		return negotiationEntry{}, false
+	}
+
+	var (
+		best        negotiationEntry
+		bestQuality float64
+		bestSpec    = -1
+		found       bool
+	)
+
+	for _, entry := range n.entries {
+		quality, spec := acceptQuality(ranges, entry.mediaType)
+		if quality <= 0 {
+			continue
+		}
+
+		better := !found ||
+			quality > bestQuality ||
+			(quality == bestQuality && spec > bestSpec) ||
+			(quality == bestQuality && spec == bestSpec && entry.mediaType == strings.ToLower(n.Default))
+
+		if better {
+			best, bestQuality, bestSpec, found = entry, quality, spec, true
+		}
+	}
+
+	return best, found
+}
+
+type acceptRange struct {
+	mediaType string
+	// base is the media type of a structured syntax suffix, like "application/json" for
+	// "application/vnd.acme.v2+json".
+	base    string
+	quality float64
+}
+
+// specificity returns 2 for exact media types, 1 for "type/*" and 0 for "*/*".
+func (r acceptRange) specificity() int {
+	switch {
+	case r.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(r.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (r acceptRange) matches(mediaType string) bool {
+	switch r.specificity() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*"))
+	default:
+		return r.mediaType == mediaType || r.base == mediaType
+	}
+}
+
+// structuredSuffixes map the structured syntax suffixes of vendor media types to their base media type.
+//
+// See https://tools.ietf.org/html/rfc6839
+var structuredSuffixes = map[string]string{
+	"+json": MIMEApplicationJSON,
+	"+xml":  MIMEApplicationXML,
+}
+
+// suffixBase returns the base media type of a vendor media type with a structured syntax suffix or an empty string.
+// Only vendor types are considered, because standard types like application/xhtml+xml are accepted by browsers, that
+// do not expect a raw application/xml response.
+func suffixBase(mediaType string) string {
+	if !strings.HasPrefix(mediaType, "application/vnd.") {
+		return ""
+	}
+
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		return structuredSuffixes[mediaType[i:]]
+	}
+
+	return ""
+}
+
+// parseAccept parses the media ranges of an Accept header. Invalid ranges are skipped.
+//
+// See https://tools.ietf.org/html/rfc7231#section-5.3.2
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.Contains(mediaType, "/") {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, acceptRange{
+			mediaType: mediaType,
+			base:      suffixBase(mediaType),
+			quality:   quality,
+		})
+	}
+
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching the media type and its specificity.
+func acceptQuality(ranges []acceptRange, mediaType string) (float64, int) {
+	var (
+		quality float64
+		spec    = -1
+	)
+
+	for _, r := range ranges {
+		if s := r.specificity(); s > spec && r.matches(mediaType) {
+			quality, spec = r.quality, s
+		}
+	}
+
+	return quality, spec
+}
@@ -0,0 +1,183 @@
+package bottleneck
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type negotiateTestValue struct {
+	Name string `json:"name" xml:"name"`
+}
+
+type negotiateTestRenderer struct{}
+
+func (negotiateTestRenderer) Header(h http.Header) {
+	h.Set(HeaderContentType, "text/csv")
+}
+
+func (negotiateTestRenderer) Render(w io.Writer) error {
+	_, err := io.WriteString(w, "name\nJake\n")
+	return err
+}
+
+func TestNegotiationChoose(t *testing.T) {
+	negotiation := NewNegotiation().Register("text/csv", func(interface{}) Renderer {
+		return negotiateTestRenderer{}
+	})
+
+	for _, tc := range []struct {
+		accept    string
+		mediaType string
+	}{
+		{accept: "", mediaType: MIMEApplicationJSON},
+		{accept: "*/*", mediaType: MIMEApplicationJSON},
+		{accept: "invalid", mediaType: MIMEApplicationJSON},
+		{accept: "application/xml", mediaType: MIMEApplicationXML},
+		{accept: "text/*", mediaType: MIMETextXML},
+		{accept: "application/json;q=0.5, text/csv", mediaType: "text/csv"},
+		{accept: "application/xml, */*", mediaType: MIMEApplicationXML},
+		{accept: "*/*, application/json;q=0", mediaType: MIMEApplicationXML},
+		{accept: "text/html", mediaType: ""},
+		{accept: "application/vnd.acme.v2+json", mediaType: MIMEApplicationJSON},
+		{accept: "application/vnd.acme+xml, application/json;q=0.5", mediaType: MIMEApplicationXML},
+		{accept: "application/vnd.acme+yaml", mediaType: ""},
+		{accept: "application/xhtml+xml", mediaType: ""},
+		{accept: "application/xhtml+xml, */*;q=0.8", mediaType: MIMEApplicationJSON},
+	} {
+		entry, ok := negotiation.choose(tc.accept)
+		assert.Equal(t, tc.mediaType != "", ok, tc.accept)
+		assert.Equal(t, tc.mediaType, entry.mediaType, tc.accept)
+	}
+}
+
+func TestContextNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			accept:      "application/json",
+			status:      http.StatusOK,
+			contentType: MIMEApplicationJSONCharsetUTF8,
+			body:        `{"name":"Jake"}`,
+		},
+		{
+			accept:      "text/xml",
+			status:      http.StatusOK,
+			contentType: MIMETextXMLCharsetUTF8,
+			body:        `<negotiateTestValue><name>Jake</name></negotiateTestValue>`,
+		},
+		{
+			accept: "text/html",
+			status: http.StatusNotAcceptable,
+		},
+		{
+			accept: "text/csv",
+			status: http.StatusNotAcceptable,
+		},
+	} {
+		var (
+			ctx      Context
+			recorder = httptest.NewRecorder()
+			req      = httptest.NewRequest(http.MethodGet, "/", nil)
+		)
+
+		req.Header.Set(HeaderAccept, tc.accept)
+		ctx.init(recorder, req, nil)
+
+		err := ctx.Negotiate(http.StatusOK, negotiateTestValue{Name: "Jake"})
+
+		if tc.status == http.StatusOK {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.contentType, recorder.Header().Get(HeaderContentType))
+			assert.Equal(t, tc.body, recorder.Body.String())
+		} else {
+			assert.Equal(t, NewError(tc.status), err)
+		}
+
+		assert.Equal(t, HeaderAccept, recorder.Header().Get(HeaderVary))
+	}
+}
+
+func TestRouterNegotiateError(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	router.Negotiation.Register(MIMETextCSV, func(value interface{}) Renderer {
+		return CSVRenderer{Value: value}
+	})
+
+	group := NewGroup()
+	group.GET("/", func(ctx *routerTestContext) error {
+		return NewError(http.StatusNotFound)
+	})
+
+	router.Mount(group)
+
+	for _, tc := range []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			accept:      "text/xml",
+			contentType: MIMETextXMLCharsetUTF8,
+			body:        `<error><status>404</status><message>Not Found</message></error>`,
+		},
+		{
+			accept:      "text/html",
+			contentType: MIMEApplicationJSONCharsetUTF8,
+			body:        `{"status":404,"message":"Not Found"}`,
+		},
+		{
+			accept:      "text/csv",
+			contentType: MIMEApplicationJSONCharsetUTF8,
+			body:        `{"status":404,"message":"Not Found"}`,
+		},
+		{
+			accept:      "application/x-ndjson",
+			contentType: MIMEApplicationJSONCharsetUTF8,
+			body:        `{"status":404,"message":"Not Found"}`,
+		},
+	} {
+		var (
+			res = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/", nil)
+		)
+
+		req.Header.Set(HeaderAccept, tc.accept)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, tc.contentType, res.Header().Get(HeaderContentType))
+		assert.Equal(t, tc.body, res.Body.String())
+	}
+}
+
+func TestRouterNegotiateRenderError(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.GET("/", func(ctx *routerTestContext) error {
+		// Maps cannot be encoded as xml.
+		return ctx.Negotiate(http.StatusOK, map[string]string{"name": "Jake"})
+	})
+
+	router.Mount(group)
+
+	var (
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+
+	req.Header.Set(HeaderAccept, "application/xml")
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, []string{MIMETextXMLCharsetUTF8}, res.Header()[HeaderContentType])
+	assert.Equal(t, `<error><status>500</status><message>Internal Server Error</message></error>`, res.Body.String())
+}
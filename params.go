@@ -0,0 +1,159 @@
+package bottleneck
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidParam indicates that a path parameter could not be converted to the requested type.
+	ErrInvalidParam = errors.New("invalid path parameter")
+
+	constraintIntPattern  = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	constraintUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// A constraint restricts the values a path parameter may have.
+type constraint func(string) bool
+
+// newConstraint creates a constraint from its definition inside angle brackets. The predefined constraints are "int"
+// and "uuid". Everything else is treated as a regular expression, that must match the whole segment.
+func newConstraint(definition string) (constraint, error) {
+	switch definition {
+	case "int":
+		return func(value string) bool {
+			if !constraintIntPattern.MatchString(value) {
+				return false
+			}
+
+			_, err := strconv.ParseInt(value, 10, 64)
+			return err == nil
+		}, nil
+
+	case "uuid":
+		return constraintUUIDPattern.MatchString, nil
+
+	default:
+		pattern, err := regexp.Compile("^(?:" + definition + ")$")
+		if err != nil {
+			return nil, err
+		}
+
+		return pattern.MatchString, nil
+	}
+}
+
+// parseConstraints removes the constraints from a path pattern and returns them by parameter name.
+//
+//   "/users/:id<int>/posts/:slug<[a-z-]+>" => "/users/:id/posts/:slug", {id: int, slug: [a-z-]+}
+func parseConstraints(path string) (string, map[string]constraint, error) {
+	var (
+		stripped    strings.Builder
+		constraints map[string]constraint
+	)
+
+	for i := 0; i < len(path); i++ {
+		stripped.WriteByte(path[i])
+
+		if path[i] != ':' && path[i] != '*' {
+			continue
+		}
+
+		nameStart := i + 1
+		for i+1 < len(path) && path[i+1] != '/' && path[i+1] != '<' {
+			i++
+			stripped.WriteByte(path[i])
+		}
+
+		if i+1 >= len(path) || path[i+1] != '<' {
+			continue
+		}
+
+		var (
+			name  = path[nameStart : i+1]
+			start = i + 2
+			depth = 1
+		)
+
+		for i++; depth > 0; {
+			i++
+			if i >= len(path) {
+				return "", nil, fmt.Errorf("unterminated constraint for parameter %q in %q", name, path)
+			}
+
+			switch path[i] {
+			case '<':
+				depth++
+			case '>':
+				depth--
+			}
+		}
+
+		c, err := newConstraint(path[start:i])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid constraint for parameter %q in %q: %w", name, path, err)
+		}
+
+		if constraints == nil {
+			constraints = make(map[string]constraint)
+		}
+
+		constraints[name] = c
+	}
+
+	return stripped.String(), constraints, nil
+}
+
+// matchConstraints tests if all constrained parameters are valid.
+func matchConstraints(constraints map[string]constraint, params map[string]string) bool {
+	for name, c := range constraints {
+		if !c(params[name]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParamInt returns the path parameter of the current matched route as int. If the parameter is not a valid integer,
+// an *Error with status 400 is returned.
+//
+//   router.GET("/users/:id<int>", func(ctx *Context) error {
+//     id, err := ctx.ParamInt("id")
+//     if err != nil {
+//       return err
+//     }
+//
+//     return ctx.JSON(http.StatusOK, findUser(id))
+//   })
+func (c *Context) ParamInt(key string) (int, error) {
+	value, err := strconv.Atoi(c.Param(key))
+	if err != nil {
+		return 0, newParamError(key, err)
+	}
+
+	return value, nil
+}
+
+// ParamUUID returns the path parameter of the current matched route as uuid.UUID. If the parameter is not a valid
+// uuid, an *Error with status 400 is returned.
+func (c *Context) ParamUUID(key string) (uuid.UUID, error) {
+	value, err := uuid.Parse(c.Param(key))
+	if err != nil {
+		return uuid.Nil, newParamError(key, err)
+	}
+
+	return value, nil
+}
+
+func newParamError(key string, cause error) *Error {
+	return NewError(http.StatusBadRequest).
+		WithMessage(fmt.Sprintf("invalid path parameter %q", key)).
+		WithCause(fmt.Errorf("%w %q: %v", ErrInvalidParam, key, cause))
+}
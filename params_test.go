@@ -0,0 +1,113 @@
+package bottleneck
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConstraints(t *testing.T) {
+	path, constraints, err := parseConstraints("/users/:id<int>/posts/:slug<[a-z-]{2,}>/:rest")
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/:id/posts/:slug/:rest", path)
+	assert.Len(t, constraints, 2)
+
+	assert.True(t, constraints["id"]("-42"))
+	assert.False(t, constraints["id"]("42a"))
+	assert.False(t, constraints["id"]("99999999999999999999"))
+	assert.True(t, constraints["slug"]("hello-world"))
+	assert.False(t, constraints["slug"]("Hello"))
+
+	_, _, err = parseConstraints("/users/:id<int")
+	assert.Error(t, err)
+
+	_, _, err = parseConstraints("/users/:id<[a-z>")
+	assert.Error(t, err)
+}
+
+func TestRouterConstraints(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.GET("/users/:id<int>", func(ctx *routerTestContext) error {
+		id, err := ctx.ParamInt("id")
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(http.StatusOK, id)
+	})
+	group.GET("/files/:id<uuid>", func(ctx *routerTestContext) error {
+		id, err := ctx.ParamUUID("id")
+		if err != nil {
+			return err
+		}
+
+		return ctx.String(http.StatusOK, id.String())
+	})
+
+	router.Mount(group)
+
+	for path, expected := range map[string]int{
+		"/users/42":  http.StatusOK,
+		"/users/joe": http.StatusNotFound,
+		"/files/b2c6c2f4-0a2c-4d7e-9c1c-3a1c9f6b1e4d": http.StatusOK,
+		"/files/42": http.StatusNotFound,
+	} {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, expected, res.Code, path)
+	}
+
+	for method, expected := range map[string]int{
+		http.MethodOptions: http.StatusNotFound,
+		http.MethodPost:    http.StatusNotFound,
+	} {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(method, "/users/joe", nil))
+		assert.Equal(t, expected, res.Code, method)
+	}
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodOptions, "/users/42", nil))
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", res.Header().Get(HeaderAllow))
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/users/42", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, res.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", res.Header().Get(HeaderAllow))
+}
+
+func TestContextParamTyped(t *testing.T) {
+	ctx := Context{
+		params: map[string]string{
+			"id":   "42",
+			"uuid": "b2c6c2f4-0a2c-4d7e-9c1c-3a1c9f6b1e4d",
+			"name": "Joe",
+		},
+	}
+
+	id, err := ctx.ParamInt("id")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	u, err := ctx.ParamUUID("uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.MustParse("b2c6c2f4-0a2c-4d7e-9c1c-3a1c9f6b1e4d"), u)
+
+	var bErr *Error
+
+	_, err = ctx.ParamInt("name")
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+
+	_, err = ctx.ParamUUID("name")
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+}
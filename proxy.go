@@ -0,0 +1,274 @@
+package bottleneck
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ProxyHeader selects the header, that trusted proxies use to forward the client. Only the selected header is used,
+// because clients can send the others as well and proxies do not remove them.
+type ProxyHeader int
+
+const (
+	// ProxyHeaderXForwardedFor uses the X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host headers.
+	ProxyHeaderXForwardedFor ProxyHeader = iota
+	// ProxyHeaderForwarded uses the Forwarded header.
+	//
+	// See https://tools.ietf.org/html/rfc7239
+	ProxyHeaderForwarded
+	// ProxyHeaderXRealIP uses the X-Real-IP header, which only contains the ip address of the client.
+	ProxyHeaderXRealIP
+)
+
+// TrustProxies sets the addresses of proxies, whose forwarding headers are used by Context.RealIP, Context.Scheme and
+// Context.Host. Both CIDRs and single ip addresses are accepted. Forwarding headers of requests from other addresses
+// are ignored. The forwarding header is selected by Router.ProxyHeader.
+//
+//   if err := router.TrustProxies("10.0.0.0/8", "fd00::/8", "127.0.0.1"); err != nil {
+//     log.Fatal(err)
+//   }
+func (r *Router) TrustProxies(addresses ...string) error {
+	networks := make([]*net.IPNet, 0, len(addresses))
+
+	for _, address := range addresses {
+		if !strings.Contains(address, "/") {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				return &net.ParseError{Type: "IP address", Text: address}
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(address)
+		if err != nil {
+			return err
+		}
+
+		networks = append(networks, network)
+	}
+
+	r.trustedProxies = networks
+	return nil
+}
+
+func (r *Router) trusts(ip net.IP) bool {
+	if r == nil || ip == nil {
+		return false
+	}
+
+	for _, network := range r.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A forwardedHop is one proxy hop described by the Forwarded or X-Forwarded-* headers.
+type forwardedHop struct {
+	ip    net.IP
+	proto string
+	host  string
+}
+
+// forwarded resolves the hop of the client. Hops are walked from the closest proxy to the client and the first hop,
+// that is not a trusted proxy, is the client. If the request does not come from a trusted proxy, the direct peer is
+// the client.
+func (c *Context) forwarded() (forwardedHop, bool) {
+	peer := forwardedHop{ip: parseIP(c.request.RemoteAddr)}
+
+	if c.router == nil || !c.router.trusts(peer.ip) {
+		return peer, false
+	}
+
+	var hops []forwardedHop
+
+	switch c.router.ProxyHeader {
+	case ProxyHeaderForwarded:
+		hops = parseForwardedHeader(c.request.Header)
+	case ProxyHeaderXRealIP:
+		if ip := parseIP(c.request.Header.Get(HeaderXRealIP)); ip != nil {
+			hops = []forwardedHop{{ip: ip}}
+		}
+	default:
+		hops = parseXForwardedHeaders(c.request.Header)
+	}
+
+	if len(hops) == 0 {
+		return peer, true
+	}
+
+	for i := len(hops) - 1; i > 0; i-- {
+		if !c.router.trusts(hops[i].ip) {
+			return hops[i], true
+		}
+	}
+
+	return hops[0], true
+}
+
+// parseForwardedHeader parses the hops of the Forwarded header.
+//
+// See https://tools.ietf.org/html/rfc7239
+func parseForwardedHeader(header http.Header) []forwardedHop {
+	var hops []forwardedHop
+
+	for _, value := range header[HeaderForwarded] {
+		for _, element := range strings.Split(value, ",") {
+			var hop forwardedHop
+
+			for _, pair := range strings.Split(element, ";") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) != 2 {
+					continue
+				}
+
+				v := strings.Trim(kv[1], `"`)
+
+				switch strings.ToLower(kv[0]) {
+				case "for":
+					hop.ip = parseIP(v)
+				case "proto":
+					hop.proto = strings.ToLower(v)
+				case "host":
+					hop.host = v
+				}
+			}
+
+			hops = append(hops, hop)
+		}
+	}
+
+	return hops
+}
+
+// parseXForwardedHeaders parses the hops of the X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host headers.
+func parseXForwardedHeaders(header http.Header) []forwardedHop {
+	var (
+		hops   []forwardedHop
+		ips    = splitHeaderList(header, HeaderXForwardedFor)
+		protos = splitHeaderList(header, HeaderXForwardedProto)
+		hosts  = splitHeaderList(header, HeaderXForwardedHost)
+	)
+
+	for i, ip := range ips {
+		hops = append(hops, forwardedHop{
+			ip:    parseIP(ip),
+			proto: strings.ToLower(pickHeaderValue(protos, i, len(ips))),
+			host:  pickHeaderValue(hosts, i, len(ips)),
+		})
+	}
+
+	if len(hops) == 0 && (len(protos) > 0 || len(hosts) > 0) {
+		hops = append(hops, forwardedHop{
+			proto: strings.ToLower(pickHeaderValue(protos, 0, 1)),
+			host:  pickHeaderValue(hosts, 0, 1),
+		})
+	}
+
+	return hops
+}
+
+func splitHeaderList(header http.Header, key string) []string {
+	var values []string
+
+	for _, value := range header[http.CanonicalHeaderKey(key)] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// pickHeaderValue returns the value belonging to the hop i of n hops. Proxies often set a single value instead of
+// appending one per hop. If the counts differ, the last value is used, because it was added by the proxy closest to
+// the server, while values in front of it may be sent by the client.
+func pickHeaderValue(values []string, i, n int) string {
+	switch {
+	case len(values) == n:
+		return values[i]
+	case len(values) > 0:
+		return values[len(values)-1]
+	default:
+		return ""
+	}
+}
+
+// parseIP parses an ip address with an optional port, brackets or an obfuscated identifier, which results in nil.
+func parseIP(address string) net.IP {
+	address = strings.TrimSpace(address)
+
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+
+	return net.ParseIP(strings.Trim(address, "[]"))
+}
+
+// RealIP returns the ip address of the client. Forwarding headers are only used, when the request comes from a
+// proxy trusted with Router.TrustProxies. An empty string is returned, if the address is unknown.
+func (c *Context) RealIP() string {
+	hop, _ := c.forwarded()
+	if hop.ip == nil {
+		// The hop may only carry a proto or host or an obfuscated identifier.
+		hop.ip = parseIP(c.request.RemoteAddr)
+	}
+
+	if hop.ip == nil {
+		return ""
+	}
+
+	return hop.ip.String()
+}
+
+// Scheme returns "https" or "http" depending on the connection of the client. Forwarding headers are only used, when
+// the request comes from a trusted proxy.
+func (c *Context) Scheme() string {
+	if hop, trusted := c.forwarded(); trusted && (hop.proto == "http" || hop.proto == "https") {
+		return hop.proto
+	}
+
+	if c.request.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// Host returns the host requested by the client. Forwarding headers are only used, when the request comes from a
+// trusted proxy.
+func (c *Context) Host() string {
+	if hop, trusted := c.forwarded(); trusted && hop.host != "" {
+		return hop.host
+	}
+
+	return c.request.Host
+}
+
+// URL builds the absolute url of a named route using the Scheme and Host of the request. See Router.URL.
+//
+//   location, err := ctx.URL("user", "id", "42") // "https://example.com/users/42"
+func (c *Context) URL(name string, params ...string) (string, error) {
+	if c.router == nil {
+		return "", ErrInvalidURL
+	}
+
+	path, err := c.router.URL(name, params...)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Scheme() + "://" + c.Host() + path, nil
+}
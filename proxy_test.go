@@ -0,0 +1,189 @@
+package bottleneck
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proxyTestContext(router *Router, remoteAddr string, header map[string]string) *Context {
+	var (
+		ctx Context
+		req = httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	)
+
+	req.RemoteAddr = remoteAddr
+
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	ctx.init(httptest.NewRecorder(), req, nil)
+	ctx.router = router
+
+	return &ctx
+}
+
+func TestRouterTrustProxies(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	assert.NoError(t, router.TrustProxies("10.0.0.0/8", "fd00::/8", "127.0.0.1"))
+	assert.Len(t, router.trustedProxies, 3)
+
+	assert.Error(t, router.TrustProxies("10.0.0.0/33"))
+	assert.Error(t, router.TrustProxies("localhost"))
+}
+
+func TestContextRealIPUntrusted(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	ctx := proxyTestContext(router, "203.0.113.7:4711", map[string]string{
+		HeaderXForwardedFor:   "198.51.100.1",
+		HeaderXForwardedProto: "https",
+		HeaderXForwardedHost:  "evil.example",
+		HeaderXRealIP:         "198.51.100.2",
+	})
+
+	assert.Equal(t, "203.0.113.7", ctx.RealIP())
+	assert.Equal(t, "http", ctx.Scheme())
+	assert.Equal(t, "example.com", ctx.Host())
+}
+
+func TestContextRealIPXForwardedFor(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("10.0.0.0/8"))
+
+	ctx := proxyTestContext(router, "10.0.0.1:4711", map[string]string{
+		HeaderXForwardedFor:   "198.51.100.1, 203.0.113.7, 10.0.0.2",
+		HeaderXForwardedProto: "https",
+		HeaderXForwardedHost:  "api.example.com",
+	})
+
+	assert.Equal(t, "203.0.113.7", ctx.RealIP())
+	assert.Equal(t, "https", ctx.Scheme())
+	assert.Equal(t, "api.example.com", ctx.Host())
+
+	ctx = proxyTestContext(router, "10.0.0.1:4711", map[string]string{
+		HeaderXForwardedFor: "10.0.0.3, 10.0.0.2",
+	})
+
+	assert.Equal(t, "10.0.0.3", ctx.RealIP())
+}
+
+func TestContextRealIPForwarded(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("10.0.0.0/8", "fd00::/8"))
+	router.ProxyHeader = ProxyHeaderForwarded
+
+	ctx := proxyTestContext(router, "[fd00::1]:4711", map[string]string{
+		HeaderForwarded:     `for="[2001:db8::7]:4711";proto=https;host=shop.example, for=10.0.0.2`,
+		HeaderXForwardedFor: "198.51.100.1",
+	})
+
+	assert.Equal(t, "2001:db8::7", ctx.RealIP())
+	assert.Equal(t, "https", ctx.Scheme())
+	assert.Equal(t, "shop.example", ctx.Host())
+
+	ctx = proxyTestContext(router, "10.0.0.1:4711", map[string]string{
+		HeaderForwarded: "for=_hidden;proto=javascript",
+	})
+
+	assert.Equal(t, "10.0.0.1", ctx.RealIP())
+	assert.Equal(t, "http", ctx.Scheme())
+}
+
+func TestContextRealIPXRealIP(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("127.0.0.1"))
+	router.ProxyHeader = ProxyHeaderXRealIP
+
+	ctx := proxyTestContext(router, "127.0.0.1:4711", map[string]string{
+		HeaderXRealIP:       "198.51.100.1",
+		HeaderXForwardedFor: "203.0.113.7",
+	})
+
+	assert.Equal(t, "198.51.100.1", ctx.RealIP())
+}
+
+func TestContextRealIPSpoofedHeaders(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("127.0.0.1"))
+
+	header := map[string]string{
+		HeaderForwarded:       "for=192.0.2.1;proto=http;host=evil.example",
+		HeaderXRealIP:         "192.0.2.2",
+		HeaderXForwardedFor:   "203.0.113.7",
+		HeaderXForwardedProto: "https",
+	}
+
+	ctx := proxyTestContext(router, "127.0.0.1:4711", header)
+
+	assert.Equal(t, "203.0.113.7", ctx.RealIP())
+	assert.Equal(t, "https", ctx.Scheme())
+	assert.Equal(t, "example.com", ctx.Host())
+
+	delete(header, HeaderXForwardedFor)
+	delete(header, HeaderXForwardedProto)
+	ctx = proxyTestContext(router, "127.0.0.1:4711", header)
+
+	assert.Equal(t, "127.0.0.1", ctx.RealIP())
+	assert.Equal(t, "http", ctx.Scheme())
+	assert.Equal(t, "example.com", ctx.Host())
+}
+
+func TestContextSchemeXForwardedProto(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("127.0.0.1"))
+
+	ctx := proxyTestContext(router, "127.0.0.1:4711", map[string]string{
+		HeaderXForwardedProto: "HTTPS",
+	})
+
+	assert.Equal(t, "127.0.0.1", ctx.RealIP())
+	assert.Equal(t, "https", ctx.Scheme())
+	assert.Equal(t, "example.com", ctx.Host())
+
+	ctx = proxyTestContext(router, "203.0.113.7:4711", nil)
+	ctx.request.TLS = &tls.ConnectionState{}
+
+	assert.Equal(t, "https", ctx.Scheme())
+}
+
+func TestContextHostSpoofedXForwardedHost(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("127.0.0.1"))
+
+	ctx := proxyTestContext(router, "127.0.0.1:4711", map[string]string{
+		HeaderXForwardedFor:   "203.0.113.7",
+		HeaderXForwardedHost:  "evil.example, example.org",
+		HeaderXForwardedProto: "http, https",
+	})
+
+	assert.Equal(t, "203.0.113.7", ctx.RealIP())
+	assert.Equal(t, "example.org", ctx.Host())
+	assert.Equal(t, "https", ctx.Scheme())
+}
+
+func TestContextURL(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	assert.NoError(t, router.TrustProxies("10.0.0.0/8"))
+
+	group := NewGroup()
+	group.GET("/users/:id", func(*Context) error { return nil }).Name("user")
+	router.Mount(group)
+
+	ctx := proxyTestContext(router, "10.0.0.1:4711", map[string]string{
+		HeaderXForwardedProto: "https",
+		HeaderXForwardedHost:  "api.example.com",
+	})
+
+	location, err := ctx.URL("user", "id", "42")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/users/42", location)
+
+	_, err = ctx.URL("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package bottleneck
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidQuery indicates that a query parameter could not be converted to the requested type.
+var ErrInvalidQuery = errors.New("invalid query parameter")
+
+// queryValues parses the query of the request once and caches the values until the request is replaced.
+func (c *Context) queryValues() url.Values {
+	if c.query == nil {
+		c.query = c.request.URL.Query()
+	}
+
+	return c.query
+}
+
+// Query returns the query value for a given key. If the key does not exist, an empty string is returned instead.
+//
+//   // curl "localhost:8080/search?input=Does router performance matter in Go?"
+//   router.GET("/search", func(ctx *Context) error {
+//     return ctx.String(http.StatusOK, ctx.Query("input"))
+//   })
+func (c *Context) Query(key string) string {
+	return c.queryValues().Get(key)
+}
+
+// QueryAll returns all query values for a given key, e.g. "?tag=a&tag=b". If the key does not exist, nil is returned.
+func (c *Context) QueryAll(key string) []string {
+	return c.queryValues()[key]
+}
+
+// QueryInt returns the query value for a given key as int. If the key does not exist or is empty, the fallback is
+// returned. If the value is not a valid integer, an *Error with status 400 is returned.
+//
+//   page, err := ctx.QueryInt("page", 1)
+func (c *Context) QueryInt(key string, fallback int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return fallback, newQueryError(key, err)
+	}
+
+	return value, nil
+}
+
+// QueryBool returns the query value for a given key as bool. Accepted values are those of strconv.ParseBool. If the
+// key does not exist or is empty, the fallback is returned. Otherwise an invalid value results in a 400 *Error.
+func (c *Context) QueryBool(key string, fallback bool) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fallback, newQueryError(key, err)
+	}
+
+	return value, nil
+}
+
+// QueryTime returns the query value for a given key parsed with the layout, e.g. time.RFC3339. If the key does not
+// exist or is empty, the fallback is returned. Otherwise an invalid value results in a 400 *Error.
+//
+//   since, err := ctx.QueryTime("since", "2006-01-02", time.Time{})
+func (c *Context) QueryTime(key, layout string, fallback time.Time) (time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := time.Parse(layout, raw)
+	if err != nil {
+		return fallback, newQueryError(key, err)
+	}
+
+	return value, nil
+}
+
+// BindQuery decodes the query into v using the "query" struct tags, just like the Binder does for GET requests. It
+// can be used without declaring a payload argument on the handler. Decoding errors result in a 400 *Error.
+//
+//   var filter struct {
+//     Tags  []string `query:"tag"`
+//     Limit int      `query:"limit"`
+//   }
+//
+//   if err := ctx.BindQuery(&filter); err != nil {
+//     return err
+//   }
+func (c *Context) BindQuery(v interface{}) error {
+	if err := decodeValues(structTagQuery, c.queryValues(), v, false); err != nil {
+		return toBadRequestError(err)
+	}
+
+	return nil
+}
+
+func newQueryError(key string, cause error) *Error {
+	return NewError(http.StatusBadRequest).
+		WithMessage(fmt.Sprintf("invalid query parameter %q", key)).
+		WithCause(fmt.Errorf("%w %q: %v", ErrInvalidQuery, key, cause))
+}
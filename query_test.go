@@ -0,0 +1,94 @@
+package bottleneck
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextQueryCache(t *testing.T) {
+	var ctx Context
+	ctx.init(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?tag=a&tag=b", nil), nil)
+
+	assert.Equal(t, "a", ctx.Query("tag"))
+	assert.Equal(t, []string{"a", "b"}, ctx.QueryAll("tag"))
+	assert.Nil(t, ctx.QueryAll("missing"))
+
+	ctx.request.URL.RawQuery = "tag=c"
+	assert.Equal(t, "a", ctx.Query("tag"))
+
+	ctx.SetRequest(httptest.NewRequest(http.MethodGet, "/?tag=c", nil))
+	assert.Equal(t, "c", ctx.Query("tag"))
+}
+
+func TestContextQueryTyped(t *testing.T) {
+	ctx := Context{
+		request: httptest.NewRequest(http.MethodGet,
+			"/?page=3&debug=true&since=2020-05-17&name=Joe&empty=", nil),
+	}
+
+	page, err := ctx.QueryInt("page", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, page)
+
+	page, err = ctx.QueryInt("missing", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page)
+
+	page, err = ctx.QueryInt("empty", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page)
+
+	debug, err := ctx.QueryBool("debug", false)
+	assert.NoError(t, err)
+	assert.True(t, debug)
+
+	since, err := ctx.QueryTime("since", "2006-01-02", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 17, 0, 0, 0, 0, time.UTC), since)
+
+	var bErr *Error
+
+	_, err = ctx.QueryInt("name", 1)
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+	assert.Equal(t, `invalid query parameter "name"`, bErr.Message)
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+
+	_, err = ctx.QueryBool("name", false)
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+
+	_, err = ctx.QueryTime("name", time.RFC3339, time.Time{})
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+}
+
+func TestContextBindQuery(t *testing.T) {
+	type filter struct {
+		Tags  []string `query:"tag"`
+		Limit int      `query:"limit"`
+	}
+
+	ctx := Context{
+		request: httptest.NewRequest(http.MethodPost, "/?tag=a&tag=b&limit=10", nil),
+	}
+
+	var f filter
+	assert.NoError(t, ctx.BindQuery(&f))
+	assert.Equal(t, filter{Tags: []string{"a", "b"}, Limit: 10}, f)
+
+	ctx = Context{
+		request: httptest.NewRequest(http.MethodGet, "/?limit=ten", nil),
+	}
+
+	var bErr *Error
+
+	err := ctx.BindQuery(&f)
+	assert.True(t, errors.As(err, &bErr))
+	assert.Equal(t, http.StatusBadRequest, bErr.Status)
+}
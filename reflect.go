@@ -98,7 +98,7 @@ func (c *contextCreator) validateTarget(targetType reflect.Type) error {
 	return nil
 }
 
-type wrappedHandler func(*contextHolder, *http.Request) error
+type wrappedHandler func(*contextHolder) error
 
 func validateHandler(c *contextCreator, t reflect.Type) error {
 	if err := assertKind(reflect.Func, t); err != nil {
@@ -131,37 +131,30 @@ func validateHandler(c *contextCreator, t reflect.Type) error {
 }
 
 func wrapHandler(router *Router, handler Handler) wrappedHandler {
+	if ws, ok := handler.(webSocketHandler); ok {
+		return wrapWebSocketHandler(router, ws.handler)
+	}
+
 	var (
 		handlerType  = reflect.TypeOf(handler)
 		handlerValue = reflect.ValueOf(handler)
-
-		payloadType reflect.Type
 	)
 
 	if err := validateHandler(router.contextCreator, handlerType); err != nil {
 		panic(err)
 	}
 
-	if handlerType.NumIn() > 1 {
-		payloadType = handlerType.In(1).Elem()
-	}
+	payload := payloadType(handler)
 
-	return func(ctx *contextHolder, req *http.Request) error {
+	return func(ctx *contextHolder) error {
 		input := make([]reflect.Value, 0, 2)
 		input = append(input, ctx.unwrap(handlerType.In(0)))
 
-		if payloadType != nil {
-			var (
-				payloadValue     = reflect.New(payloadType)
-				payloadInterface = payloadValue.Interface()
-			)
-
-			if err := router.Binder.Bind(req, payloadInterface); err != nil {
-				return toBadRequestError(err)
-			}
+		if payload != nil {
+			payloadValue := reflect.New(payload)
 
-			if err := router.Validator.Validate(req, payloadInterface); err != nil {
-				return toBadRequestError(err)
+			if err := ctx.baseContext.BindAndValidate(payloadValue.Interface()); err != nil {
+				return err
 			}
 
 			input = append(input, payloadValue)
@@ -175,6 +168,15 @@ func wrapHandler(router *Router, handler Handler) wrappedHandler {
 	}
 }
 
+// payloadType returns the struct type of the payload argument of a handler or nil, if there is none.
+func payloadType(handler Handler) reflect.Type {
+	if t := reflect.TypeOf(handler); t != nil && t.Kind() == reflect.Func && t.NumIn() > 1 {
+		return t.In(1).Elem()
+	}
+
+	return nil
+}
+
 func toBadRequestError(err error) error {
 	if _, ok := err.(*Error); ok {
 		return err
@@ -1,10 +1,17 @@
 package bottleneck
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
 )
 
 // A Renderer is a container type that wraps an abstract http response.
@@ -37,6 +44,9 @@ func (r StringRenderer) Render(w io.Writer) error {
 // JSONRenderer implements the Renderer interface.
 type JSONRenderer struct {
 	Value interface{}
+	// Options define how the Value is encoded. If it is nil, Context.Render uses the JSONOptions of the Context and
+	// DefaultJSONOptions are used otherwise.
+	Options *JSONOptions
 }
 
 // Header sets the Content-Type to "application/json; charset=UTF8".
@@ -44,15 +54,18 @@ func (JSONRenderer) Header(h http.Header) {
 	h.Add(HeaderContentType, MIMEApplicationJSONCharsetUTF8)
 }
 
-// Render marshals the Value as JSON and then writes it to w.
+// Render encodes the Value as JSON and then writes it to w.
 func (r JSONRenderer) Render(w io.Writer) error {
-	b, err := json.Marshal(r.Value)
-	if err != nil {
-		return err
+	opts := DefaultJSONOptions
+	if r.Options != nil {
+		opts = *r.Options
 	}
 
-	_, err = w.Write(b)
-	return err
+	if opts.Stream {
+		return streamJSON(w, r.Value, opts)
+	}
+
+	return encodeJSON(w, r.Value, opts)
 }
 
 // XMLRenderer implements the Renderer interface.
@@ -92,3 +105,166 @@ func (r StreamRenderer) Render(w io.Writer) error {
 	_, err := io.Copy(w, r.Reader)
 	return err
 }
+
+// YAMLRenderer implements the Renderer interface.
+type YAMLRenderer struct {
+	Value interface{}
+}
+
+// Header sets the Content-Type to "application/yaml; charset=UTF8".
+func (YAMLRenderer) Header(h http.Header) {
+	h.Add(HeaderContentType, MIMEApplicationYAMLCharsetUTF8)
+}
+
+// Render marshals the Value as YAML and then writes it to w.
+func (r YAMLRenderer) Render(w io.Writer) error {
+	b, err := yaml.Marshal(r.Value)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// CSVRenderer implements the Renderer interface.
+type CSVRenderer struct {
+	// Value is either a [][]string, which is written as is, or a slice of structs. The header row of struct slices is
+	// built from the "csv" tags of the fields or their names. Fields tagged with "-" are skipped.
+	Value interface{}
+}
+
+// Header sets the Content-Type to "text/csv; charset=UTF8".
+func (CSVRenderer) Header(h http.Header) {
+	h.Add(HeaderContentType, MIMETextCSVCharsetUTF8)
+}
+
+// Render writes the Value as CSV to w.
+func (r CSVRenderer) Render(w io.Writer) error {
+	records, err := csvRecords(r.Value)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	return writer.WriteAll(records)
+}
+
+// NDJSONRenderer implements the Renderer interface.
+type NDJSONRenderer struct {
+	// Values is a slice, a receive channel or a func(yield func(interface{}) error) error. Every value is written as a
+	// single line of JSON. Values of channels and funcs are written as they arrive and are not buffered.
+	Values interface{}
+}
+
+// Header sets the Content-Type to "application/x-ndjson".
+func (NDJSONRenderer) Header(h http.Header) {
+	h.Add(HeaderContentType, MIMEApplicationNDJSON)
+}
+
+// Render writes every value of Values as a line of JSON to w. If w is a http.Flusher, it is flushed after every line.
+func (r NDJSONRenderer) Render(w io.Writer) error {
+	var (
+		encoder    = json.NewEncoder(w)
+		flusher, _ = w.(http.Flusher)
+	)
+
+	yield := func(value interface{}) error {
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		return nil
+	}
+
+	if iterate, ok := r.Values.(func(func(interface{}) error) error); ok {
+		return iterate(yield)
+	}
+
+	v := reflect.ValueOf(r.Values)
+
+	switch v.Kind() {
+	case reflect.Chan:
+		for {
+			value, ok := v.Recv()
+			if !ok {
+				return nil
+			}
+
+			if err := yield(value.Interface()); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := yield(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		return yield(r.Values)
+	}
+}
+
+// MessagePackRenderer implements the Renderer interface.
+type MessagePackRenderer struct {
+	// Value is encoded using the "msgpack" struct tags. Fields without one fall back to their "json" tag.
+	Value interface{}
+}
+
+// Header sets the Content-Type to "application/msgpack".
+func (MessagePackRenderer) Header(h http.Header) {
+	h.Add(HeaderContentType, MIMEApplicationMessagePack)
+}
+
+// Render encodes the Value as MessagePack and then writes it to w.
+func (r MessagePackRenderer) Render(w io.Writer) error {
+	b, err := marshalMessagePack(r.Value)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// ErrInvalidCallback indicates that a JSONP callback is not a valid javascript identifier.
+var ErrInvalidCallback = errors.New("invalid jsonp callback")
+
+var jsonpCallbackPattern = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+// JSONPRenderer implements the Renderer interface.
+type JSONPRenderer struct {
+	// Callback is the name of the javascript function. It may only contain identifiers separated by dots.
+	Callback string
+	Value    interface{}
+}
+
+// Header sets the Content-Type to "application/javascript; charset=UTF8".
+func (JSONPRenderer) Header(h http.Header) {
+	h.Add(HeaderContentType, MIMEApplicationJavaScriptCharsetUTF8)
+}
+
+// Render marshals the Value as JSON and writes it to w wrapped in a call of Callback.
+func (r JSONPRenderer) Render(w io.Writer) error {
+	if !jsonpCallbackPattern.MatchString(r.Callback) {
+		return ErrInvalidCallback
+	}
+
+	b, err := json.Marshal(r.Value)
+	if err != nil {
+		return err
+	}
+
+	// The leading comment prevents the response from being interpreted as a flash file.
+	_, err = fmt.Fprintf(w, "/**/ typeof %s === 'function' && %s(%s);", r.Callback, r.Callback, b)
+	return err
+}
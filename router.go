@@ -1,12 +1,19 @@
 package bottleneck
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strings"
-
-	"github.com/dimfeld/httptreemux/v5"
 )
 
+// ErrInvalidURL indicates that Router.URL could not build a path.
+var ErrInvalidURL = errors.New("invalid url")
+
 // A Handler must be a func with either 1 or 2 arguments and returning an error.
 // The first must be either *bottleneck.Context or a pointer to a struct, that embeds bottleneck.Context.
 // The second is optional and, if provided, must be a pointer to a struct, which is used to unmarshal and validate
@@ -56,20 +63,66 @@ type Middleware interface{}
 // Next is the second argument for Middleware. When called it will continue the route handling and return future errors.
 type Next func() error
 
+// RouteInfo describes a route of a Router.
+type RouteInfo struct {
+	// Host is the pattern of MountHost or empty, if the route is used for all hosts.
+	Host string
+	// Method is the http method of the route.
+	Method string
+	// Path is the full path pattern of the route including the prefixes of all groups and parameter constraints.
+	Path string
+	// Version is the api version of the route or empty, if the route is used for all versions.
+	Version string
+	// Payload is the struct type of the second handler argument or nil, if the handler does not accept a payload.
+	Payload reflect.Type
+	// WebSocket reports whether the route was added with Group.WebSocket.
+	WebSocket bool
+	// Name is the name given with Group.Name or empty.
+	Name string
+}
+
 type route struct {
+	host       string
+	name       string
 	method     string
 	path       string
+	version    string
 	handler    Handler
 	middleware []Middleware
+	// shared is the number of leading middleware, that are added to the route by groups rather than for the route
+	// alone.
+	shared int
 }
 
 // A Router is a multiplexer for http requests.
+//
+// GET routes also answer HEAD requests without sending the body. OPTIONS requests are answered with an Allow header
+// listing the registered methods of a path. The automatic answer runs through the group middleware of the route, that
+// handles the method in the Access-Control-Request-Method header, or of the first route of the path otherwise. This
+// way middleware like CORS can answer preflight requests, while middleware added for single routes is skipped.
+// Explicitly registered HEAD and OPTIONS routes take precedence.
 type Router struct {
-	mux            *httptreemux.TreeMux
+	tree           *tree
+	hosts          []*hostTree
 	contextCreator *contextCreator
-
-	Binder    Binder
-	Validator Validator
+	routes         []route
+	templates      *htmlTemplates
+	trustedProxies []*net.IPNet
+
+	Binder      Binder
+	Validator   Validator
+	Versioning  Versioning
+	Negotiation *Negotiation
+	JSON        JSONOptions
+	Cookies     CookieOptions
+
+	// ProxyHeader selects the forwarding header of proxies trusted with TrustProxies. The default is
+	// ProxyHeaderXForwardedFor.
+	ProxyHeader ProxyHeader
+
+	// CheckOrigin decides whether a websocket upgrade is allowed for the request. If it is nil, the Origin header must
+	// be missing or match the Host of the request.
+	CheckOrigin func(req *http.Request) bool
 }
 
 // NewRouter creates a new Router for a custom context. The provided contextValue is an example instance of the context,
@@ -82,33 +135,192 @@ type Router struct {
 //   router := NewRouter(CustomContext{})
 //   router.Listen(":8080")
 func NewRouter(contextValue interface{}) *Router {
-	return &Router{
-		mux:            httptreemux.New(),
+	r := &Router{
 		contextCreator: newContextCreator(contextValue),
 
-		Binder:    DefaultBinder,
-		Validator: DefaultValidator,
+		Binder:      DefaultBinder,
+		Validator:   DefaultValidator,
+		Versioning:  DefaultVersioning,
+		Negotiation: NewNegotiation(),
+		JSON:        DefaultJSONOptions,
 	}
+
+	r.tree = newTree(r)
+	return r
 }
 
-// Mount adds all routes of a Group to the Router.
+// Mount adds all routes of a Group to the Router. These routes are used for all hosts, that are not matched by a
+// pattern of MountHost.
 func (r *Router) Mount(g *Group) *Router {
 	for _, route := range g.routes {
-		r.mux.Handle(route.method, route.path, makeMuxHandler(r, route))
+		r.tree.handle(route)
+		r.routes = append(r.routes, route)
+	}
+
+	return r
+}
+
+// MountHost adds all routes of a Group to the Router, which are only used for requests to a matching host. Labels of
+// the pattern starting with a colon match any single label and are available through Context.Param. The port of a
+// request is ignored. Patterns are tested in the order they are mounted.
+//
+//   router.MountHost("admin.example.com", adminGroup)
+//   router.MountHost(":tenant.example.com", tenantGroup)
+//
+//   tenantGroup.GET("/", func(ctx *Context) error {
+//     return ctx.String(http.StatusOK, ctx.Param("tenant"))
+//   })
+func (r *Router) MountHost(pattern string, g *Group) *Router {
+	host := r.hostTree(pattern)
+
+	for _, route := range g.routes {
+		route.host = pattern
+		host.tree.handle(route)
+		r.routes = append(r.routes, route)
 	}
 
 	return r
 }
 
+func (r *Router) hostTree(pattern string) *hostTree {
+	for _, host := range r.hosts {
+		if host.pattern == pattern {
+			return host
+		}
+	}
+
+	host := &hostTree{
+		pattern: pattern,
+		labels:  parseHostPattern(pattern),
+		tree:    newTree(r),
+	}
+
+	r.hosts = append(r.hosts, host)
+	return host
+}
+
+// serveError renders an error, that occurs outside of a route, through the regular error handling.
+func (r *Router) serveError(res http.ResponseWriter, req *http.Request, err error) {
+	ctx := r.newContext(res, req, nil)
+	handleError(ctx.baseContext, err)
+}
+
+func (r *Router) newContext(res http.ResponseWriter, req *http.Request, params map[string]string) *contextHolder {
+	ctx := r.contextCreator.create(res, req, params)
+	ctx.baseContext.router = r
+	return ctx
+}
+
+// URL builds the path of a named route. The params are pairs of parameter names and values, which are escaped and
+// inserted into the path.
+//
+//   path, err := router.URL("user", "id", "42") // "/users/42"
+func (r *Router) URL(name string, params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("%w: odd number of params for %s", ErrInvalidURL, name)
+	}
+
+	for _, route := range r.routes {
+		if route.name == name {
+			return buildURL(route.path, params)
+		}
+	}
+
+	return "", fmt.Errorf("%w: unknown route %s", ErrInvalidURL, name)
+}
+
+func buildURL(pattern string, params []string) (string, error) {
+	pattern, _, err := parseConstraints(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	segments := strings.Split(pattern, "/")
+
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		value, ok := values[segment[1:]]
+		if !ok {
+			return "", fmt.Errorf("%w: missing param %s", ErrInvalidURL, segment[1:])
+		}
+
+		if segment[0] == '*' {
+			segments[i] = (&url.URL{Path: strings.TrimPrefix(value, "/")}).EscapedPath()
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// Routes returns a description of all routes, that have been mounted onto the Router, in the order they were added.
+// It can be used to generate documentation or clients for the Router.
+func (r *Router) Routes() []RouteInfo {
+	infos := make([]RouteInfo, len(r.routes))
+
+	for i, route := range r.routes {
+		_, webSocket := route.handler.(webSocketHandler)
+
+		infos[i] = RouteInfo{
+			Host:      route.host,
+			Method:    route.method,
+			Path:      route.path,
+			Version:   route.version,
+			Payload:   payloadType(route.handler),
+			WebSocket: webSocket,
+			Name:      route.name,
+		}
+	}
+
+	return infos
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (r *Router) ServeHTTP(res http.ResponseWriter, req *http.Request) {
-	result, _ := r.mux.Lookup(res, req)
-	r.mux.ServeLookupResult(res, req, result)
+	t := r.tree
+
+	for _, host := range r.hosts {
+		if params, ok := host.match(req.Host); ok {
+			if len(params) > 0 {
+				req = req.WithContext(context.WithValue(req.Context(), hostParamsKey{}, params))
+			}
+
+			t = host.tree
+			break
+		}
+	}
+
+	result, _ := t.mux.Lookup(res, req)
+
+	if result.StatusCode == http.StatusMethodNotAllowed {
+		// The mux does not provide the params of a path without a handler for the method. Every path has an OPTIONS
+		// handler though, so the endpoint is looked up again to check the constraints before answering with 405.
+		options := req.WithContext(context.WithValue(req.Context(), methodNotAllowedKey{}, req))
+		options.Method = http.MethodOptions
+
+		result, _ = t.mux.Lookup(res, options)
+		req = options
+	}
+
+	t.mux.ServeLookupResult(res, req, result)
 }
 
-// A Group is a collection of routes. A Group may have a prefix, that is shared across all routes.
+// methodNotAllowedKey marks a request, that is looked up again to answer with 405.
+type methodNotAllowedKey struct{}
+
+// A Group is a collection of routes. A Group may have a prefix and a version, that are shared across all routes.
 type Group struct {
 	prefix     string
+	version    string
 	routes     []route
 	middleware []Middleware
 }
@@ -124,11 +336,28 @@ func (g *Group) WithPrefix(prefix string) *Group {
 	return g
 }
 
-// Mount adds all routes of the subgroups to this Group.
+// WithVersion restricts all routes of the Group to requests for the api version. The requested version is determined
+// by Router.Versioning. Routes without version answer requests, that do not specify a version, and all requests to
+// paths without any versioned route. Requests for a version, that a versioned path has no route for, are answered
+// with 406.
+//
+//   v1 := NewGroup().WithVersion("1")
+//   v1.GET("/users", listUsersV1)
+//
+//   v2 := NewGroup().WithVersion("2")
+//   v2.GET("/users", listUsersV2)
+func (g *Group) WithVersion(version string) *Group {
+	g.version = version
+	return g
+}
+
+// Mount adds all routes of the subgroups to this Group. Routes of subgroups keep their version.
 func (g *Group) Mount(subgroups ...*Group) *Group {
 	for _, subgroup := range subgroups {
 		for _, route := range subgroup.routes {
-			g.Add(route.method, route.path, route.handler, route.middleware...)
+			g.add(route.method, route.path, route.version, route.handler, route.middleware)
+			g.routes[len(g.routes)-1].name = route.name
+			g.routes[len(g.routes)-1].shared = len(g.middleware) + route.shared
 		}
 	}
 
@@ -148,20 +377,41 @@ func (g *Group) relativePath(path string) string {
 
 // Add adds a Handler to the Group.
 func (g *Group) Add(method, path string, handler Handler, middleware ...Middleware) *Group {
+	return g.add(method, path, "", handler, middleware)
+}
+
+func (g *Group) add(method, path, version string, handler Handler, middleware []Middleware) *Group {
 	m := make([]Middleware, len(g.middleware)+len(middleware))
 	copy(m, g.middleware)
 	copy(m[len(g.middleware):], middleware)
 
+	if version == "" {
+		version = g.version
+	}
+
 	g.routes = append(g.routes, route{
 		method:     method,
 		path:       g.relativePath(path),
+		version:    version,
 		handler:    handler,
 		middleware: m,
+		shared:     len(g.middleware),
 	})
 
 	return g
 }
 
+// Name names the route, that was added last, so that its path can be built with Router.URL.
+//
+//   group.GET("/users/:id", showUser).Name("user")
+func (g *Group) Name(name string) *Group {
+	if len(g.routes) > 0 {
+		g.routes[len(g.routes)-1].name = name
+	}
+
+	return g
+}
+
 // GET adds a Handler to the Group with the "GET" http method.
 func (g *Group) GET(path string, handler Handler, middleware ...Middleware) *Group {
 	return g.Add(http.MethodGet, path, handler, middleware...)
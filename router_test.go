@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -139,3 +140,152 @@ func TestRouterServeBottleneckError(t *testing.T) {
 	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, res.Header().Get(HeaderContentType))
 	assert.Equal(t, `{"status":411,"message":"Custom Error Message"}`, buf.String())
 }
+
+func TestRouterRoutes(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup().WithPrefix("/api")
+	group.GET("/", func(*routerTestContext) error { return nil })
+	group.POST("/:id", func(*routerTestContext, *routerTestRequest) error { return nil })
+	group.WebSocket("/live", func(*routerTestContext, *WebSocketConn) error { return nil })
+
+	router.Mount(group)
+
+	assert.Equal(t, []RouteInfo{
+		{Method: http.MethodGet, Path: "/api/"},
+		{Method: http.MethodPost, Path: "/api/:id", Payload: reflect.TypeOf(routerTestRequest{})},
+		{Method: http.MethodGet, Path: "/api/live", WebSocket: true},
+	}, router.Routes())
+}
+
+func TestRouterHead(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.GET("/implicit", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusAccepted, "Hello World")
+	})
+	group.GET("/explicit", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "Hello World")
+	})
+	group.HEAD("/explicit", func(ctx *routerTestContext) error {
+		ctx.Response().WriteHeader(http.StatusTeapot)
+		return nil
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodHead, "/implicit", nil))
+
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	assert.Equal(t, "11", res.Header().Get(HeaderContentLength))
+	assert.Equal(t, 0, res.Body.Len())
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodHead, "/explicit", nil))
+
+	assert.Equal(t, http.StatusTeapot, res.Code)
+}
+
+func TestRouterOptions(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.GET("/implicit", func(*routerTestContext) error { return nil })
+	group.POST("/implicit", func(*routerTestContext) error { return nil })
+	group.DELETE("/explicit", func(*routerTestContext) error { return nil })
+	group.OPTIONS("/explicit", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "Custom")
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodOptions, "/implicit", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST", res.Header().Get(HeaderAllow))
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodOptions, "/explicit", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "Custom", res.Body.String())
+}
+
+func TestRouterOptionsMiddleware(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.Use(func(ctx *routerTestContext, next Next) error {
+		ctx.Response().Header().Set("X-Middleware", "called")
+
+		if ctx.Request().Header.Get("X-Reject") != "" {
+			return NewError(http.StatusForbidden)
+		}
+
+		return next()
+	})
+	group.GET("/", func(*routerTestContext) error { return nil }, func(ctx *routerTestContext, next Next) error {
+		ctx.Response().Header().Set("X-Route", "called")
+		return next()
+	})
+
+	router.Mount(NewGroup().Mount(group))
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodOptions, "/", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", res.Header().Get(HeaderAllow))
+	assert.Equal(t, "called", res.Header().Get("X-Middleware"))
+	assert.Empty(t, res.Header().Get("X-Route"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("X-Reject", "true")
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.GET("/", func(*routerTestContext) error { return nil })
+	group.PUT("/", func(*routerTestContext) error { return nil })
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, res.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, PUT", res.Header().Get(HeaderAllow))
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, res.Header().Get(HeaderContentType))
+	assert.Equal(t, `{"status":405,"message":"Method Not Allowed"}`, res.Body.String())
+}
+
+func TestRouterSharedValues(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.Use(func(ctx *Context, next Next) error {
+		ctx.Set("user", "Jake")
+		return next()
+	})
+	group.GET("/", func(ctx *routerTestContext) error {
+		user, _ := ctx.Get("user")
+		return ctx.String(http.StatusOK, user.(string))
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "Jake", res.Body.String())
+}
@@ -0,0 +1,193 @@
+package bottleneck
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamClosed indicates that an event could not be sent, because the client disconnected or the stream was
+// closed.
+var ErrStreamClosed = errors.New("event stream closed")
+
+// SSEOptions define how an EventStream behaves.
+type SSEOptions struct {
+	// Retry is sent to the client as reconnection time, if it is greater than zero.
+	Retry time.Duration
+	// Heartbeat is the interval in which comments are sent to keep the connection alive. Zero disables heartbeats.
+	Heartbeat time.Duration
+	// Replay is called with the value of the Last-Event-ID header, when a client reconnects. It can be used to send
+	// the events the client missed.
+	Replay func(lastEventID string, stream *EventStream) error
+}
+
+// An EventStream writes Server-Sent Events to the client. It is safe for concurrent use.
+//
+// See https://html.spec.whatwg.org/multipage/server-sent-events.html
+type EventStream struct {
+	mutex    sync.Mutex
+	response *Response
+	done     chan struct{}
+	stop     chan struct{}
+	closed   bool
+}
+
+// SSE sends the header for a stream of Server-Sent Events and returns the EventStream to write events. The stream
+// is stopped automatically, when the client disconnects. Handlers should wait for Done and Close the stream before
+// they return.
+//
+//   router.GET("/events", func(ctx *Context) error {
+//     stream, err := ctx.SSE(SSEOptions{Heartbeat: 15 * time.Second})
+//     if err != nil {
+//       return err
+//     }
+//
+//     defer stream.Close()
+//
+//     for {
+//       select {
+//       case <-stream.Done():
+//         return nil
+//       case update := <-updates:
+//         if err := stream.Send("update", update.ID, update.Text); err != nil {
+//           return err
+//         }
+//       }
+//     }
+//   })
+func (c *Context) SSE(opts SSEOptions) (*EventStream, error) {
+	res := c.Response()
+
+	res.Header().Set(HeaderContentType, MIMETextEventStream)
+	res.Header().Set(HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	stream := &EventStream{
+		response: res,
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+
+	go stream.watch(c.RequestContext().Done())
+
+	if opts.Retry > 0 {
+		if err := stream.write(fmt.Sprintf("retry: %d\n\n", opts.Retry.Milliseconds())); err != nil {
+			return nil, err
+		}
+	} else {
+		res.Flush()
+	}
+
+	if lastEventID := c.Request().Header.Get(HeaderLastEventID); lastEventID != "" && opts.Replay != nil {
+		if err := opts.Replay(lastEventID, stream); err != nil {
+			stream.Close()
+			return nil, err
+		}
+	}
+
+	if opts.Heartbeat > 0 {
+		go stream.heartbeat(opts.Heartbeat)
+	}
+
+	return stream, nil
+}
+
+// Send writes an event to the client. The event name and id are optional. Multi-line data is split into multiple
+// data fields.
+func (s *EventStream) Send(event, id, data string) error {
+	var buf strings.Builder
+
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", stripNewlines(event))
+	}
+
+	if id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", stripNewlines(id))
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+
+	buf.WriteString("\n")
+	return s.write(buf.String())
+}
+
+// Comment writes a comment, which is ignored by clients.
+func (s *EventStream) Comment(text string) error {
+	return s.write(": " + stripNewlines(text) + "\n\n")
+}
+
+// Done returns a channel, that is closed when the client disconnects or the stream is closed.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// watch closes done, when the client disconnects or the stream is closed.
+func (s *EventStream) watch(disconnected <-chan struct{}) {
+	select {
+	case <-disconnected:
+	case <-s.stop:
+	}
+
+	close(s.done)
+}
+
+// Close stops the heartbeat. Events cannot be sent after the stream is closed.
+func (s *EventStream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+}
+
+func (s *EventStream) write(text string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	select {
+	case <-s.done:
+		return ErrStreamClosed
+	default:
+	}
+
+	if _, err := io.WriteString(s.response, text); err != nil {
+		return err
+	}
+
+	s.response.Flush()
+	return nil
+}
+
+func (s *EventStream) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Comment("heartbeat"); err != nil {
+				return
+			}
+
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,90 @@
+package bottleneck
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextSSE(t *testing.T) {
+	var (
+		ctx      Context
+		recorder = httptest.NewRecorder()
+		req      = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+
+	req.Header.Set(HeaderLastEventID, "1")
+	ctx.init(recorder, req, nil)
+
+	stream, err := ctx.SSE(SSEOptions{
+		Retry: 3 * time.Second,
+		Replay: func(lastEventID string, stream *EventStream) error {
+			return stream.Send("", "2", "missed "+lastEventID)
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, stream.Send("update", "3", "line 1\nline 2"))
+	assert.NoError(t, stream.Comment("ping"))
+
+	stream.Close()
+	<-stream.Done()
+
+	assert.True(t, errors.Is(stream.Send("", "", "closed"), ErrStreamClosed))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, MIMETextEventStream, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, "no-cache", recorder.Header().Get(HeaderCacheControl))
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, "retry: 3000\n\n"+
+		"id: 2\ndata: missed 1\n\n"+
+		"event: update\nid: 3\ndata: line 1\ndata: line 2\n\n"+
+		": ping\n\n", recorder.Body.String())
+}
+
+func TestContextSSEDisconnect(t *testing.T) {
+	var (
+		router       = NewRouter(routerTestContext{})
+		group        = NewGroup()
+		disconnected = make(chan struct{})
+	)
+
+	group.GET("/events", func(ctx *routerTestContext) error {
+		stream, err := ctx.SSE(SSEOptions{Heartbeat: 10 * time.Millisecond})
+		if err != nil {
+			return err
+		}
+
+		defer stream.Close()
+
+		<-stream.Done()
+		close(disconnected)
+		return nil
+	})
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events")
+	assert.NoError(t, err)
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, ": heartbeat"))
+
+	res.Body.Close()
+
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("stream was not stopped after the client disconnected")
+	}
+}
@@ -0,0 +1,114 @@
+package bottleneck
+
+import (
+	"net/http"
+	"strings"
+)
+
+const mountParam = "bottleneckMountPath"
+
+// mountMethods are the http methods, that are forwarded to a handler mounted with Group.MountHandler.
+var mountMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// Handle adds a standard http.Handler to the Group. The handler is called after all Middleware of the route.
+//
+//   group.Handle(http.MethodGet, "/metrics", promhttp.Handler())
+func (g *Group) Handle(method, path string, handler http.Handler, middleware ...Middleware) *Group {
+	return g.Add(method, path, wrapHTTPHandler(handler), middleware...)
+}
+
+// MountHandler adds a standard http.Handler for all requests to the prefix and paths below it. The handler receives
+// requests with the prefix removed from the path, similar to http.StripPrefix.
+//
+//   group.MountHandler("/debug/pprof", http.DefaultServeMux)
+//   group.MountHandler("/legacy", legacyMux)
+func (g *Group) MountHandler(prefix string, handler http.Handler, middleware ...Middleware) *Group {
+	var (
+		mounted = mountHTTPHandler(handler)
+		base    = strings.TrimSuffix(prefix, "/")
+	)
+
+	for _, method := range mountMethods {
+		if base != "" {
+			g.Add(method, base, mounted, middleware...)
+		}
+
+		g.Add(method, base+"/*"+mountParam, mounted, middleware...)
+	}
+
+	return g
+}
+
+func wrapHTTPHandler(handler http.Handler) func(*Context) error {
+	return func(ctx *Context) error {
+		handler.ServeHTTP(ctx.Response(), ctx.Request())
+		return nil
+	}
+}
+
+// mountHTTPHandler calls the handler with the path of the request rewritten to the part matched by the catch-all
+// parameter of MountHandler.
+func mountHTTPHandler(handler http.Handler) func(*Context) error {
+	return func(ctx *Context) error {
+		req := ctx.Request()
+
+		u := *req.URL
+		u.Path = "/" + strings.TrimPrefix(ctx.Param(mountParam), "/")
+		u.RawPath = ""
+
+		// The mux removes trailing slashes from catch-all parameters.
+		if strings.HasSuffix(req.URL.Path, "/") && !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+
+		r := req.WithContext(req.Context())
+		r.URL = &u
+
+		handler.ServeHTTP(ctx.Response(), r)
+		return nil
+	}
+}
+
+// WrapHTTPMiddleware converts a standard net/http middleware into a Middleware.
+//
+// The standard middleware writes to the Response of the Context, so that the status, Before functions and the
+// writer set by other middleware are respected. The request and response writer, that it passes on, replace the ones
+// of the Context for the rest of the chain. The original Response is restored after the chain returns.
+//
+//   group.Use(bottleneck.WrapHTTPMiddleware(handlers.ProxyHeaders))
+func WrapHTTPMiddleware(middleware func(http.Handler) http.Handler) func(*Context, Next) error {
+	return func(ctx *Context, next Next) error {
+		var (
+			original = ctx.Response()
+			err      error
+		)
+
+		defer func() {
+			ctx.response = original
+		}()
+
+		handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if w != original {
+				// The writer usually wraps the original Response, so it cannot become its Writer.
+				ctx.response = &Response{
+					Status: original.Status,
+					Writer: w,
+				}
+			}
+
+			ctx.SetRequest(r)
+			err = next()
+		}))
+
+		handler.ServeHTTP(original, ctx.Request())
+		return err
+	}
+}
@@ -0,0 +1,152 @@
+package bottleneck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stdTestKey struct{}
+
+func TestGroupHandle(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	group := NewGroup()
+	group.Handle(http.MethodGet, "/std", http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusAccepted)
+		res.Write([]byte(req.URL.Path))
+	}))
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/std", nil))
+
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	assert.Equal(t, "/std", res.Body.String())
+}
+
+func TestGroupMountHandler(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(req.Method + " " + req.URL.Path))
+	})
+
+	group := NewGroup().WithPrefix("/api")
+	group.MountHandler("/legacy/", mux)
+
+	router.Mount(group)
+
+	for target, expected := range map[string]string{
+		"/api/legacy":           "/",
+		"/api/legacy/users/42":  "/users/42",
+		"/api/legacy/users/42/": "/users/42/",
+	} {
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, httptest.NewRequest(method, target, nil))
+
+			assert.Equal(t, http.StatusOK, res.Code, target)
+			assert.Equal(t, method+" "+expected, res.Body.String(), target)
+		}
+	}
+}
+
+func TestWrapHTTPMiddleware(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Set("X-Std", "before")
+			ctx := context.WithValue(req.Context(), stdTestKey{}, "value")
+			next.ServeHTTP(&upperCaseWriter{res}, req.WithContext(ctx))
+		})
+	}
+
+	group := NewGroup()
+	group.Use(WrapHTTPMiddleware(std))
+	group.POST("/", func(ctx *routerTestContext, req *routerTestRequest) error {
+		value := ctx.Request().Context().Value(stdTestKey{}).(string)
+		return ctx.String(http.StatusOK, value+" "+req.Payload)
+	})
+
+	router.Mount(group)
+
+	var (
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"payload": "Jake"}`))
+		res = httptest.NewRecorder()
+	)
+
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "before", res.Header().Get("X-Std"))
+	assert.Equal(t, "VALUE JAKE", res.Body.String())
+}
+
+func TestWrapHTTPMiddlewareResponse(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		status int
+		before bool
+	)
+
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if _, deny := req.URL.Query()["deny"]; deny {
+				res.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(&upperCaseWriter{res}, req)
+		})
+	}
+
+	group := NewGroup()
+	group.Use(func(ctx *routerTestContext, next Next) error {
+		ctx.Response().Before(func() {
+			before = true
+		})
+
+		err := next()
+		status = ctx.Response().Status
+		return err
+	})
+	group.Use(WrapHTTPMiddleware(std))
+	group.GET("/", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusCreated, "created")
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "CREATED", res.Body.String())
+	assert.Equal(t, http.StatusCreated, status)
+	assert.True(t, before)
+
+	before = false
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/?deny", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.True(t, before)
+}
+
+type upperCaseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *upperCaseWriter) Write(b []byte) (int, error) {
+	return w.ResponseWriter.Write([]byte(strings.ToUpper(string(b))))
+}
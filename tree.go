@@ -0,0 +1,216 @@
+package bottleneck
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/dimfeld/httptreemux/v5"
+)
+
+// A tree holds the routes for a set of hosts.
+type tree struct {
+	router    *Router
+	mux       *httptreemux.TreeMux
+	endpoints map[string]*endpoint
+}
+
+func newTree(router *Router) *tree {
+	t := &tree{
+		router:    router,
+		mux:       httptreemux.New(),
+		endpoints: make(map[string]*endpoint),
+	}
+
+	return t
+}
+
+func (t *tree) handle(route route) {
+	path, constraints, err := parseConstraints(route.path)
+	if err != nil {
+		panic(err)
+	}
+
+	// The mux treats paths with and without a trailing slash as the same node.
+	key := path
+	if len(key) > 1 {
+		key = strings.TrimSuffix(key, "/")
+	}
+
+	e, ok := t.endpoints[key]
+	if !ok {
+		e = &endpoint{
+			router:   t.router,
+			handlers: make(map[string][]variant),
+			notFound: t.mux.NotFoundHandler,
+		}
+
+		t.endpoints[key] = e
+		t.mux.Handle(http.MethodOptions, path, e.serveOptions)
+	}
+
+	for _, v := range e.handlers[route.method] {
+		if v.version == route.version {
+			panic(fmt.Sprintf("%s already handles %s (version %q)", route.path, route.method, route.version))
+		}
+	}
+
+	if _, ok := e.handlers[route.method]; !ok && route.method != http.MethodOptions {
+		t.mux.Handle(route.method, path, e.dispatcher(route.method))
+	}
+
+	v := variant{
+		version:     route.version,
+		constraints: constraints,
+		handler:     makeMuxHandler(t.router, route, constraints, t.mux.NotFoundHandler),
+		// Middleware like CORS must see OPTIONS requests, even if they are answered automatically. Middleware added
+		// for the route alone, like authentication, is not meant for them.
+		options: makeChain(wrapMiddlewareList(t.router, route.middleware[:route.shared]), e.answerOptions),
+	}
+
+	if e.options == nil {
+		e.options = v.options
+	}
+
+	e.handlers[route.method] = append(e.handlers[route.method], v)
+}
+
+// A variant is the handler for a specific version of a route.
+type variant struct {
+	version     string
+	constraints map[string]constraint
+	handler     httptreemux.HandlerFunc
+	// options answers OPTIONS requests automatically through the group middleware of the route.
+	options wrappedHandler
+}
+
+// An endpoint collects the handlers, that are registered for a single path.
+type endpoint struct {
+	router   *Router
+	handlers map[string][]variant
+	notFound http.HandlerFunc
+	// options is the options chain of the first route, that is used if the request does not name a method.
+	options wrappedHandler
+}
+
+// methods returns the methods of the endpoint, that have a variant with matching constraints. HEAD is implied by GET
+// and OPTIONS is added, if any other method matches.
+func (e *endpoint) methods(params map[string]string) map[string]bool {
+	methods := make(map[string]bool)
+
+	for method, variants := range e.handlers {
+		for _, v := range variants {
+			if matchConstraints(v.constraints, params) {
+				methods[method] = true
+				break
+			}
+		}
+	}
+
+	if len(methods) > 0 {
+		methods[http.MethodOptions] = true
+	}
+
+	if methods[http.MethodGet] {
+		methods[http.MethodHead] = true
+	}
+
+	return methods
+}
+
+func (e *endpoint) dispatcher(method string) httptreemux.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request, params map[string]string) {
+		e.dispatch(method, res, req, params)
+	}
+}
+
+// dispatch calls the variant of a method, that matches the requested version. If there is none, 406 is returned.
+func (e *endpoint) dispatch(method string, res http.ResponseWriter, req *http.Request, params map[string]string) {
+	if v := e.router.Versioning.choose(req, e.handlers[method]); v != nil {
+		v.handler(res, req, params)
+		return
+	}
+
+	e.router.serveError(res, req, NewError(http.StatusNotAcceptable))
+}
+
+// serveOptions calls the explicitly registered OPTIONS handler or answers with the allowed methods otherwise. The
+// automatic answer runs through the group middleware of the route named by Access-Control-Request-Method. Requests,
+// that the Router looked up again because of an unsupported method, are answered with 405 instead.
+func (e *endpoint) serveOptions(res http.ResponseWriter, req *http.Request, params map[string]string) {
+	if original, ok := req.Context().Value(methodNotAllowedKey{}).(*http.Request); ok {
+		e.methodNotAllowed(res, original, params)
+		return
+	}
+
+	if len(e.handlers[http.MethodOptions]) > 0 {
+		e.dispatch(http.MethodOptions, res, req, params)
+		return
+	}
+
+	params = withHostParams(req, params)
+
+	methods := e.methods(params)
+	if len(methods) == 0 {
+		e.notFound(res, req)
+		return
+	}
+
+	ctx := e.router.newContext(res, req, params)
+	defer ctx.baseContext.release()
+
+	ctx.baseContext.Response().Header().Set(HeaderAllow, joinMethods(methods))
+
+	if err := e.optionsChain(req, params)(ctx); err != nil {
+		handleError(ctx.baseContext, err)
+	}
+}
+
+// optionsChain returns the options chain of the variant, that handles the method requested by a CORS preflight
+// request. Other requests use the chain of the first route of the path.
+func (e *endpoint) optionsChain(req *http.Request, params map[string]string) wrappedHandler {
+	var (
+		method   = req.Header.Get(HeaderAccessControlRequestMethod)
+		variants []variant
+	)
+
+	for _, v := range e.handlers[method] {
+		if matchConstraints(v.constraints, params) {
+			variants = append(variants, v)
+		}
+	}
+
+	if v := e.router.Versioning.choose(req, variants); v != nil {
+		return v.options
+	}
+
+	return e.options
+}
+
+func (e *endpoint) answerOptions(ctx *contextHolder) error {
+	return ctx.baseContext.NoContent(http.StatusNoContent)
+}
+
+// methodNotAllowed renders a 405 error with an Allow header through the regular error handling. If the constraints of
+// no method match, the path is not found at all.
+func (e *endpoint) methodNotAllowed(res http.ResponseWriter, req *http.Request, params map[string]string) {
+	methods := e.methods(withHostParams(req, params))
+	if len(methods) == 0 {
+		e.notFound(res, req)
+		return
+	}
+
+	res.Header().Set(HeaderAllow, joinMethods(methods))
+	e.router.serveError(res, req, NewError(http.StatusMethodNotAllowed))
+}
+
+func joinMethods(methods map[string]bool) string {
+	list := make([]string, 0, len(methods))
+	for method := range methods {
+		list = append(list, method)
+	}
+
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
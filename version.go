@@ -0,0 +1,134 @@
+package bottleneck
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultVersioning is the default Versioning, that does not extract a version from requests. Only routes without
+// version are matched.
+var DefaultVersioning = Versioning{}
+
+// A VersionSource extracts the requested api version from a request. An empty string is returned, if the request
+// does not specify a version.
+type VersionSource func(*http.Request) string
+
+// Versioning defines how the api version of a request is determined to choose between routes of the same method and
+// path, that are added with Group.WithVersion.
+//
+//   router.Versioning = bottleneck.Versioning{
+//     Source:  bottleneck.MediaTypeVersion("acme"),
+//     Default: "1",
+//   }
+type Versioning struct {
+	// Source extracts the version from requests. If nil, every request uses the Default.
+	Source VersionSource
+	// Default is the version used for requests, that do not specify a version.
+	Default string
+}
+
+// choose returns the variant for the requested version. A variant without version is only used for requests, that do
+// not specify a version, or if the path is not versioned at all. If no variant matches, nil is returned.
+func (v *Versioning) choose(req *http.Request, variants []variant) *variant {
+	// The Source is always called, because sources like QueryVersion remove the version from the request.
+	var requested string
+	if v.Source != nil {
+		requested = v.Source(req)
+	}
+
+	if len(variants) == 1 && variants[0].version == "" {
+		return &variants[0]
+	}
+
+	explicit := requested != ""
+	if !explicit {
+		requested = v.Default
+	}
+
+	var fallback *variant
+
+	for i := range variants {
+		switch variants[i].version {
+		case requested:
+			return &variants[i]
+		case "":
+			fallback = &variants[i]
+		}
+	}
+
+	if explicit {
+		// An unknown version must not silently be answered by another variant.
+		return nil
+	}
+
+	return fallback
+}
+
+// HeaderVersion creates a VersionSource, that reads the version from a custom http header.
+//
+//   X-API-Version: 2
+func HeaderVersion(header string) VersionSource {
+	return func(req *http.Request) string {
+		return strings.TrimSpace(req.Header.Get(header))
+	}
+}
+
+// QueryVersion creates a VersionSource, that reads the version from a query parameter. The parameter is removed from
+// the query, so that it does not fail the binding of payloads, which do not know the parameter.
+//
+//   /users?version=2
+func QueryVersion(key string) VersionSource {
+	return func(req *http.Request) string {
+		query := req.URL.Query()
+
+		version, ok := query[key]
+		if !ok {
+			return ""
+		}
+
+		query.Del(key)
+		req.URL.RawQuery = query.Encode()
+
+		if len(version) == 0 {
+			return ""
+		}
+
+		return version[0]
+	}
+}
+
+// MediaTypeVersion creates a VersionSource, that reads the version from the Accept header. The version is either
+// part of a vendor specific media type or a "version" parameter.
+//
+//   Accept: application/vnd.acme.v2+json
+//   Accept: application/json; version=2
+func MediaTypeVersion(vendor string) VersionSource {
+	prefix := "application/vnd." + strings.ToLower(vendor) + ".v"
+
+	return func(req *http.Request) string {
+		for _, accept := range strings.Split(req.Header.Get(HeaderAccept), ",") {
+			mediaType, params, err := mime.ParseMediaType(accept)
+			if err != nil {
+				continue
+			}
+
+			if version := params["version"]; version != "" {
+				return version
+			}
+
+			if vendor != "" && strings.HasPrefix(mediaType, prefix) {
+				version := mediaType[len(prefix):]
+				if i := strings.IndexByte(version, '+'); i >= 0 {
+					version = version[:i]
+				}
+
+				if version != "" {
+					return version
+				}
+			}
+		}
+
+		return ""
+	}
+}
@@ -0,0 +1,129 @@
+package bottleneck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionSources(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?v=3", nil)
+	req.Header.Set("X-API-Version", " 2 ")
+	req.Header.Set(HeaderAccept, "text/html, application/vnd.acme.v4+json;q=0.9")
+
+	assert.Equal(t, "2", HeaderVersion("X-API-Version")(req))
+	assert.Equal(t, "3", QueryVersion("v")(req))
+	assert.Equal(t, "4", MediaTypeVersion("acme")(req))
+	assert.Equal(t, "", MediaTypeVersion("other")(req))
+
+	req.Header.Set(HeaderAccept, "application/json; version=5")
+	assert.Equal(t, "5", MediaTypeVersion("acme")(req))
+}
+
+func TestRouterVersioning(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		v1     = NewGroup().WithVersion("1")
+		v2     = NewGroup().WithVersion("2")
+	)
+
+	router.Versioning = Versioning{
+		Source:  MediaTypeVersion("acme"),
+		Default: "1",
+	}
+
+	v1.GET("/users", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "v1")
+	})
+	v2.GET("/users", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "v2")
+	})
+
+	router.Mount(NewGroup().Mount(v1, v2))
+
+	for accept, expected := range map[string][2]interface{}{
+		"":                             {http.StatusOK, "v1"},
+		"application/vnd.acme.v1+json": {http.StatusOK, "v1"},
+		"application/vnd.acme.v2+json": {http.StatusOK, "v2"},
+		"application/vnd.acme.v3+json": {http.StatusNotAcceptable, `{"status":406,"message":"Not Acceptable"}`},
+	} {
+		var (
+			req = httptest.NewRequest(http.MethodGet, "/users", nil)
+			res = httptest.NewRecorder()
+		)
+
+		req.Header.Set(HeaderAccept, accept)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, expected[0], res.Code, accept)
+		assert.Equal(t, expected[1], res.Body.String(), accept)
+	}
+
+	assert.Panics(t, func() {
+		router.Mount(NewGroup().WithVersion("2").GET("/users", func(*routerTestContext) error { return nil }))
+	})
+}
+
+func TestRouterVersioningFallback(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	router.Versioning = Versioning{Source: HeaderVersion("X-API-Version")}
+
+	group := NewGroup()
+	group.GET("/users", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "default")
+	})
+	group.GET("/health", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	v2 := NewGroup().WithVersion("2")
+	v2.GET("/users", func(ctx *routerTestContext) error {
+		return ctx.String(http.StatusOK, "v2")
+	})
+
+	router.Mount(group.Mount(v2))
+
+	for _, test := range []struct {
+		path, version string
+		status        int
+	}{
+		{"/users", "", http.StatusOK},
+		{"/users", "2", http.StatusOK},
+		{"/users", "3", http.StatusNotAcceptable},
+		{"/health", "3", http.StatusOK},
+	} {
+		var (
+			req = httptest.NewRequest(http.MethodGet, test.path, nil)
+			res = httptest.NewRecorder()
+		)
+
+		req.Header.Set("X-API-Version", test.version)
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, test.status, res.Code, test.path+" "+test.version)
+	}
+}
+
+type versionTestQuery struct {
+	Name string `query:"name"`
+}
+
+func TestRouterQueryVersionStripped(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+	router.Versioning = Versioning{Source: QueryVersion("version")}
+
+	group := NewGroup()
+	group.GET("/users", func(ctx *routerTestContext, query *versionTestQuery) error {
+		return ctx.String(http.StatusOK, query.Name)
+	})
+
+	router.Mount(group)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/users?version=2&name=joe", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "joe", res.Body.String())
+}
@@ -0,0 +1,625 @@
+package bottleneck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Some well-known websocket close codes.
+//
+// See https://tools.ietf.org/html/rfc6455#section-7.4.1
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseInternalServerErr       = 1011
+)
+
+// MessageType is the type of a websocket data message.
+type MessageType int
+
+// The websocket message types.
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	maxControlPayload = 125
+)
+
+// DefaultWebSocketReadLimit is the read limit of new connections in bytes. It can be changed with
+// WebSocketConn.SetReadLimit.
+const DefaultWebSocketReadLimit = 1 << 20
+
+var (
+	// ErrWebSocketClosed indicates that a message could not be written, because the connection is closed.
+	ErrWebSocketClosed = errors.New("websocket closed")
+	// ErrWebSocketReadLimit indicates that a message exceeded the read limit of the connection.
+	ErrWebSocketReadLimit = errors.New("websocket read limit exceeded")
+
+	errWebSocketProtocol = errors.New("websocket protocol error")
+	errWebSocketUTF8     = fmt.Errorf("%w: invalid utf-8", errWebSocketProtocol)
+)
+
+// entityHeaders describe a response body and must not be sent with the handshake, because the upgraded connection
+// has none. They are typically set by middleware like compression before the handler is called.
+var entityHeaders = []string{
+	HeaderContentEncoding,
+	HeaderContentLength,
+	HeaderContentType,
+	HeaderVary,
+	"Content-Language",
+	"Transfer-Encoding",
+}
+
+// A CloseError is returned by WebSocketConn.ReadMessage, when the client closes the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error formats the error as readable text.
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed: code=%d reason=%s", e.Code, e.Reason)
+}
+
+// A WebSocketHandler must be a func with exactly two arguments and returning an error.
+// The first must be either *bottleneck.Context or a pointer to a struct, that embeds bottleneck.Context.
+// The second must be *bottleneck.WebSocketConn.
+//
+//   func echo(ctx *CustomContext, conn *bottleneck.WebSocketConn) error {
+//     for {
+//       messageType, message, err := conn.ReadMessage()
+//       if err != nil {
+//         return err
+//       }
+//
+//       if err := conn.WriteMessage(messageType, message); err != nil {
+//         return err
+//       }
+//     }
+//   }
+type WebSocketHandler interface{}
+
+// webSocketHandler marks a route handler as WebSocketHandler.
+type webSocketHandler struct {
+	handler WebSocketHandler
+}
+
+// WebSocket adds a WebSocketHandler to the Group with the "GET" http method. Middleware is called before the
+// connection is upgraded, so errors of Middleware are handled as usual. After the upgrade errors can no longer be
+// rendered. The connection is closed with CloseInternalServerErr instead.
+//
+// Cross-origin upgrades are rejected with 403, unless Router.CheckOrigin allows them.
+func (g *Group) WebSocket(path string, handler WebSocketHandler, middleware ...Middleware) *Group {
+	return g.GET(path, webSocketHandler{handler}, middleware...)
+}
+
+var webSocketConnType = reflect.TypeOf((*WebSocketConn)(nil))
+
+func validateWebSocketHandler(c *contextCreator, t reflect.Type) error {
+	if err := assertKind(reflect.Func, t); err != nil {
+		return errors.New("websocket handler must be a func")
+	}
+
+	if t.NumIn() != 2 {
+		return errors.New("websocket handler must have exactly two arguments")
+	}
+
+	if err := c.validateTarget(t.In(0)); err != nil {
+		return err
+	}
+
+	if err := assertType(webSocketConnType, t.In(1)); err != nil {
+		return err
+	}
+
+	if t.NumOut() != 1 || t.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		return errors.New("websocket handler must return exactly one value of type error")
+	}
+
+	return nil
+}
+
+// wrapWebSocketHandler upgrades the connection and calls the handler. Errors after the upgrade are still returned, so
+// that Middleware can observe them, but they are not rendered, because the response is already committed.
+func wrapWebSocketHandler(router *Router, handler WebSocketHandler) wrappedHandler {
+	var (
+		handlerType  = reflect.TypeOf(handler)
+		handlerValue = reflect.ValueOf(handler)
+	)
+
+	if err := validateWebSocketHandler(router.contextCreator, handlerType); err != nil {
+		panic(err)
+	}
+
+	return func(ctx *contextHolder) error {
+		conn, err := upgradeWebSocket(router, ctx.baseContext.Response(), ctx.baseContext.Request())
+		if err != nil {
+			return err
+		}
+
+		input := []reflect.Value{ctx.unwrap(handlerType.In(0)), reflect.ValueOf(conn)}
+
+		if output := handlerValue.Call(input)[0]; !output.IsNil() {
+			err = output.Interface().(error)
+		}
+
+		var closeErr *CloseError
+
+		switch {
+		case err == nil:
+			return conn.Close(CloseNormalClosure, "")
+
+		case errors.As(err, &closeErr):
+			// The client closed the connection, which is the regular end of a websocket.
+			return nil
+
+		default:
+			conn.Close(CloseInternalServerErr, "")
+			return err
+		}
+	}
+}
+
+// sameOrigin reports whether the Origin header of a request is missing or matches its Host.
+func sameOrigin(req *http.Request) bool {
+	origin := req.Header.Get(HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// upgradeWebSocket performs the opening handshake. Errors before the connection is hijacked are returned as *Error.
+func upgradeWebSocket(router *Router, res *Response, req *http.Request) (*WebSocketConn, error) {
+	if req.Method != http.MethodGet ||
+		!headerContainsToken(req.Header, "Connection", "upgrade") ||
+		!headerContainsToken(req.Header, "Upgrade", "websocket") {
+		return nil, NewError(http.StatusBadRequest).WithMessage("not a websocket handshake")
+	}
+
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		res.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, NewError(http.StatusUpgradeRequired).WithMessage("unsupported websocket version")
+	}
+
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, NewError(http.StatusBadRequest).WithMessage("invalid websocket key")
+	}
+
+	checkOrigin := router.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+
+	if !checkOrigin(req) {
+		return nil, NewError(http.StatusForbidden).WithMessage("websocket origin not allowed")
+	}
+
+	conn, rw, err := res.Hijack()
+	if err != nil {
+		return nil, NewError(http.StatusInternalServerError).WithCause(err)
+	}
+
+	// Deadlines set by the http server do not apply to the upgraded connection.
+	conn.SetDeadline(time.Time{}) // nolint:errcheck
+
+	header := res.Header()
+	for _, name := range entityHeaders {
+		header.Del(name)
+	}
+
+	header.Set("Upgrade", "websocket")
+	header.Set("Connection", "Upgrade")
+	header.Set("Sec-WebSocket-Accept", webSocketAccept(key))
+
+	res.Status = http.StatusSwitchingProtocols
+	res.runBefore()
+
+	// Before functions may have added entity headers again.
+	for _, name := range entityHeaders {
+		header.Del(name)
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", res.Status, http.StatusText(res.Status))
+	header.Write(rw)       // nolint:errcheck
+	rw.WriteString("\r\n") // nolint:errcheck
+
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &WebSocketConn{
+		conn:      conn,
+		rw:        rw,
+		readLimit: DefaultWebSocketReadLimit,
+	}, nil
+}
+
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+webSocketGUID) // nolint:errcheck
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// A WebSocketConn is an upgraded websocket connection. Reading is not safe for concurrent use, but messages may be
+// written concurrently to reading.
+type WebSocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	readLimit   int64
+	pongHandler func([]byte)
+
+	writeMutex sync.Mutex
+	closeSent  bool
+}
+
+// SetReadLimit sets the maximum size of a message in bytes. Larger messages close the connection with
+// CloseMessageTooBig. The default is DefaultWebSocketReadLimit. A limit <= 0 disables the limit, which lets clients
+// send messages of any size.
+func (c *WebSocketConn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// SetPongHandler sets a function, that is called with the payload of every pong received.
+func (c *WebSocketConn) SetPongHandler(handler func([]byte)) {
+	c.pongHandler = handler
+}
+
+// SetReadDeadline sets the deadline for future reads. See net.Conn.
+func (c *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for future writes. See net.Conn.
+func (c *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// RemoteAddr returns the network address of the client.
+func (c *WebSocketConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// ReadMessage reads the next data message. Pings are answered and pongs are passed to the pong handler while
+// reading. If the client closes the connection, the close is acknowledged and a *CloseError is returned.
+func (c *WebSocketConn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		messageType MessageType
+		message     []byte
+	)
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+
+			continue
+
+		case opPong:
+			if c.pongHandler != nil {
+				c.pongHandler(payload)
+			}
+
+			continue
+
+		case opClose:
+			return 0, nil, c.handleClose(payload)
+
+		case opText, opBinary:
+			if messageType != 0 {
+				return 0, nil, c.fail(fmt.Errorf("%w: expected continuation frame", errWebSocketProtocol))
+			}
+
+			messageType = MessageType(opcode)
+
+		case opContinuation:
+			if messageType == 0 {
+				return 0, nil, c.fail(fmt.Errorf("%w: unexpected continuation frame", errWebSocketProtocol))
+			}
+
+		default:
+			return 0, nil, c.fail(fmt.Errorf("%w: unknown opcode %d", errWebSocketProtocol, opcode))
+		}
+
+		if c.readLimit > 0 && int64(len(message)+len(payload)) > c.readLimit {
+			return 0, nil, c.fail(ErrWebSocketReadLimit)
+		}
+
+		message = append(message, payload...)
+
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(errWebSocketUTF8)
+			}
+
+			return messageType, message, nil
+		}
+	}
+}
+
+// WriteMessage sends a data message to the client.
+func (c *WebSocketConn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("%w: invalid message type %d", errWebSocketProtocol, messageType)
+	}
+
+	return c.writeFrame(byte(messageType), data)
+}
+
+// Ping sends a ping to the client. The payload must not be longer than 125 bytes.
+func (c *WebSocketConn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return fmt.Errorf("%w: control frame too large", errWebSocketProtocol)
+	}
+
+	return c.writeFrame(opPing, data)
+}
+
+// Close sends a close frame with the code and reason and closes the underlying connection.
+func (c *WebSocketConn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+
+	if len(payload) > maxControlPayload {
+		payload = payload[:maxControlPayload]
+	}
+
+	err := c.writeFrame(opClose, payload)
+	if errors.Is(err, ErrWebSocketClosed) {
+		err = nil
+	}
+
+	if closeErr := c.conn.Close(); err == nil && !isClosedConnError(closeErr) {
+		err = closeErr
+	}
+
+	return err
+}
+
+// handleClose acknowledges a close frame of the client.
+func (c *WebSocketConn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+
+	switch {
+	case len(payload) == 1:
+		c.Close(CloseProtocolError, "")
+		return fmt.Errorf("%w: invalid close payload", errWebSocketProtocol)
+
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+
+		if !validCloseCode(closeErr.Code) {
+			return c.fail(fmt.Errorf("%w: invalid close code %d", errWebSocketProtocol, closeErr.Code))
+		}
+
+		if !utf8.ValidString(closeErr.Reason) {
+			return c.fail(errWebSocketUTF8)
+		}
+	}
+
+	if closeErr.Code == CloseNoStatusReceived {
+		c.Close(CloseNormalClosure, "")
+	} else {
+		c.Close(closeErr.Code, "")
+	}
+
+	return closeErr
+}
+
+// validCloseCode reports whether a client may send the close code. Codes reserved for local use, like
+// CloseNoStatusReceived and CloseAbnormalClosure, or without meaning are invalid.
+//
+// See https://tools.ietf.org/html/rfc6455#section-7.4.2
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003:
+		return true
+	case code >= 1007 && code <= 1014:
+		return true
+	default:
+		return code >= 3000 && code <= 4999
+	}
+}
+
+// fail closes the connection with a close code matching the error.
+func (c *WebSocketConn) fail(err error) error {
+	switch {
+	case errors.Is(err, ErrWebSocketReadLimit):
+		c.Close(CloseMessageTooBig, "")
+
+	case errors.Is(err, errWebSocketUTF8):
+		c.Close(CloseInvalidFramePayloadData, "")
+
+	case errors.Is(err, errWebSocketProtocol):
+		c.Close(CloseProtocolError, "")
+
+	default:
+		c.conn.Close()
+	}
+
+	return err
+}
+
+func (c *WebSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.rw, header[:]); err != nil {
+		return
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+
+	if header[0]&0x70 != 0 {
+		err = fmt.Errorf("%w: reserved bits set", errWebSocketProtocol)
+		return
+	}
+
+	if header[1]&0x80 == 0 {
+		err = fmt.Errorf("%w: client frames must be masked", errWebSocketProtocol)
+		return
+	}
+
+	length := int64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.rw, ext[:]); err != nil {
+			return
+		}
+
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.rw, ext[:]); err != nil {
+			return
+		}
+
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+		if length < 0 {
+			err = fmt.Errorf("%w: invalid frame length", errWebSocketProtocol)
+			return
+		}
+	}
+
+	if opcode >= opClose && (!fin || length > maxControlPayload) {
+		err = fmt.Errorf("%w: invalid control frame", errWebSocketProtocol)
+		return
+	}
+
+	if c.readLimit > 0 && length > c.readLimit {
+		err = ErrWebSocketReadLimit
+		return
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.rw, mask[:]); err != nil {
+		return
+	}
+
+	// The payload is read in chunks instead of allocating the length claimed by the client up front.
+	var buf bytes.Buffer
+	if _, err = io.CopyN(&buf, c.rw, length); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return
+	}
+
+	payload = buf.Bytes()
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return
+}
+
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closeSent {
+		return ErrWebSocketClosed
+	}
+
+	header := []byte{0x80 | opcode, 0}
+	length := len(payload)
+
+	switch {
+	case length <= 125:
+		header[1] = byte(length)
+
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	if opcode == opClose {
+		c.closeSent = true
+	}
+
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+
+	return c.rw.Flush()
+}
+
+func isClosedConnError(err error) bool {
+	return err == nil || strings.Contains(err.Error(), "use of closed network connection")
+}
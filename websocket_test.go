@@ -0,0 +1,390 @@
+package bottleneck
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type webSocketTestClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWebSocketTest(t *testing.T, server *httptest.Server, path string, header http.Header) (*webSocketTestClient, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	assert.NoError(t, req.Write(conn))
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return &webSocketTestClient{conn: conn, reader: reader}, res
+}
+
+func (c *webSocketTestClient) writeFrame(fin bool, opcode byte, payload []byte) error {
+	var (
+		mask  = []byte{1, 2, 3, 4}
+		first = opcode
+		frame []byte
+	)
+
+	if fin {
+		first |= 0x80
+	}
+
+	switch {
+	case len(payload) <= 125:
+		frame = []byte{first, 0x80 | byte(len(payload))}
+	default:
+		frame = []byte{first, 0x80 | 126, 0, 0}
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	}
+
+	frame = append(frame, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *webSocketTestClient) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, length)
+	_, err := io.ReadFull(c.reader, payload)
+	return header[0] & 0x0F, payload, err
+}
+
+func closeCode(payload []byte) int {
+	if len(payload) < 2 {
+		return 0
+	}
+
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+		closed = make(chan error, 1)
+	)
+
+	group.WebSocket("/echo", func(ctx *routerTestContext, conn *WebSocketConn) error {
+		conn.SetReadLimit(1024)
+
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return err
+			}
+
+			if err := conn.WriteMessage(messageType, message); err != nil {
+				return err
+			}
+		}
+	})
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client, res := dialWebSocketTest(t, server, "/echo", nil)
+	defer client.conn.Close()
+
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Header.Get("Sec-WebSocket-Accept"))
+
+	assert.NoError(t, client.writeFrame(true, opText, []byte("hello")))
+	opcode, payload, err := client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opText), opcode)
+	assert.Equal(t, "hello", string(payload))
+
+	// fragmented message with an interleaved ping
+	assert.NoError(t, client.writeFrame(false, opBinary, []byte{1, 2}))
+	assert.NoError(t, client.writeFrame(true, opPing, []byte("ping")))
+	assert.NoError(t, client.writeFrame(true, opContinuation, []byte{3}))
+
+	opcode, payload, err = client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opPong), opcode)
+	assert.Equal(t, "ping", string(payload))
+
+	opcode, payload, err = client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opBinary), opcode)
+	assert.Equal(t, []byte{1, 2, 3}, payload)
+
+	closePayload := []byte{0, 0, 'b', 'y', 'e'}
+	binary.BigEndian.PutUint16(closePayload, CloseGoingAway)
+	assert.NoError(t, client.writeFrame(true, opClose, closePayload))
+
+	opcode, payload, err = client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opClose), opcode)
+	assert.Equal(t, CloseGoingAway, closeCode(payload))
+
+	var closeErr *CloseError
+	assert.True(t, errors.As(<-closed, &closeErr))
+	assert.Equal(t, CloseGoingAway, closeErr.Code)
+	assert.Equal(t, "bye", closeErr.Reason)
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+		result = make(chan error, 1)
+	)
+
+	group.WebSocket("/", func(ctx *Context, conn *WebSocketConn) error {
+		conn.SetReadLimit(4)
+		_, _, err := conn.ReadMessage()
+		result <- err
+		return err
+	})
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client, _ := dialWebSocketTest(t, server, "/", nil)
+	defer client.conn.Close()
+
+	assert.NoError(t, client.writeFrame(true, opText, []byte("too long")))
+
+	opcode, payload, err := client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opClose), opcode)
+	assert.Equal(t, CloseMessageTooBig, closeCode(payload))
+	assert.True(t, errors.Is(<-result, ErrWebSocketReadLimit))
+}
+
+func TestWebSocketDefaultReadLimit(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+		result = make(chan error, 1)
+	)
+
+	group.WebSocket("/", func(ctx *Context, conn *WebSocketConn) error {
+		_, _, err := conn.ReadMessage()
+		result <- err
+		return err
+	})
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client, _ := dialWebSocketTest(t, server, "/", nil)
+	defer client.conn.Close()
+
+	// Only the header of a frame, that claims a payload of 1 TiB.
+	header := []byte{0x80 | opBinary, 0x80 | 127, 0, 0, 0, 1, 0, 0, 0, 0, 1, 2, 3, 4}
+	_, err := client.conn.Write(header)
+	assert.NoError(t, err)
+
+	opcode, payload, err := client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opClose), opcode)
+	assert.Equal(t, CloseMessageTooBig, closeCode(payload))
+	assert.True(t, errors.Is(<-result, ErrWebSocketReadLimit))
+}
+
+func TestWebSocketHandlerError(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+	)
+
+	group.WebSocket("/", func(ctx *Context, conn *WebSocketConn) error {
+		return errors.New("failed")
+	})
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client, _ := dialWebSocketTest(t, server, "/", nil)
+	defer client.conn.Close()
+
+	opcode, payload, err := client.readFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(opClose), opcode)
+	assert.Equal(t, CloseInternalServerErr, closeCode(payload))
+}
+
+func TestWebSocketBeforeUpgrade(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+	)
+
+	auth := func(ctx *Context, next Next) error {
+		if ctx.Request().Header.Get("Authorization") != "secret" {
+			return NewError(http.StatusUnauthorized)
+		}
+
+		return next()
+	}
+
+	group.WebSocket("/", func(ctx *Context, conn *WebSocketConn) error {
+		return nil
+	}, auth)
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		header http.Header
+		status int
+	}{
+		{header: http.Header{}, status: http.StatusUnauthorized},
+		{header: http.Header{"Authorization": {"secret"}, "Sec-Websocket-Version": {"8"}}, status: http.StatusUpgradeRequired},
+		{header: http.Header{"Authorization": {"secret"}, "Origin": {"http://evil.example"}}, status: http.StatusForbidden},
+		{header: http.Header{"Authorization": {"secret"}}, status: http.StatusSwitchingProtocols},
+	} {
+		client, res := dialWebSocketTest(t, server, "/", tc.header)
+		client.conn.Close()
+
+		assert.Equal(t, tc.status, res.StatusCode)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestWebSocketInvalidClose(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+	)
+
+	group.WebSocket("/", func(ctx *Context, conn *WebSocketConn) error {
+		_, _, err := conn.ReadMessage()
+		return err
+	})
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		code     int
+		reason   string
+		expected int
+	}{
+		{code: CloseNoStatusReceived, expected: CloseProtocolError},
+		{code: CloseAbnormalClosure, expected: CloseProtocolError},
+		{code: 1015, expected: CloseProtocolError},
+		{code: 999, expected: CloseProtocolError},
+		{code: 5000, expected: CloseProtocolError},
+		{code: CloseNormalClosure, reason: "\xff", expected: CloseInvalidFramePayloadData},
+		{code: 4000, reason: "custom", expected: 4000},
+	} {
+		client, _ := dialWebSocketTest(t, server, "/", nil)
+
+		payload := make([]byte, 2, 2+len(tc.reason))
+		binary.BigEndian.PutUint16(payload, uint16(tc.code))
+		assert.NoError(t, client.writeFrame(true, opClose, append(payload, tc.reason...)))
+
+		opcode, payload, err := client.readFrame()
+		assert.NoError(t, err)
+		assert.Equal(t, byte(opClose), opcode)
+		assert.Equal(t, tc.expected, closeCode(payload), tc.code)
+
+		client.conn.Close()
+	}
+}
+
+func TestWebSocketHandshakeHeader(t *testing.T) {
+	var (
+		router = NewRouter(routerTestContext{})
+		group  = NewGroup()
+	)
+
+	compress := func(ctx *Context, next Next) error {
+		header := ctx.Response().Header()
+		header.Set(HeaderContentEncoding, "gzip")
+		header.Add(HeaderVary, HeaderAcceptEncoding)
+		header.Set("X-Custom", "value")
+		return next()
+	}
+
+	group.WebSocket("/", func(ctx *Context, conn *WebSocketConn) error {
+		return nil
+	}, compress)
+
+	router.Mount(group)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client, res := dialWebSocketTest(t, server, "/", nil)
+	defer client.conn.Close()
+
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "value", res.Header.Get("X-Custom"))
+	assert.Empty(t, res.Header.Get(HeaderContentEncoding))
+	assert.Empty(t, res.Header.Get(HeaderVary))
+}
+
+func TestWebSocketInvalidHandler(t *testing.T) {
+	router := NewRouter(routerTestContext{})
+
+	assert.Panics(t, func() {
+		router.Mount(NewGroup().WebSocket("/", func(ctx *Context) error { return nil }))
+	})
+}